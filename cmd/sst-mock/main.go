@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/joeshaw/envdecode"
+	_ "github.com/joho/godotenv/autoload"
+	zerolog "github.com/rs/zerolog/log"
+
+	"sstcloud-alice-gateway/internal/log"
+	"sstcloud-alice-gateway/pkg/sst/ssttest"
+)
+
+type config struct {
+	Logger   log.Config
+	Address  string `env:"SST_MOCK_ADDRESS,default=:8081"`
+	EMail    string `env:"SST_MOCK_EMAIL,default=demo@example.com"`
+	Password string `env:"SST_MOCK_PASSWORD,default=demo"`
+	Houses   int    `env:"SST_MOCK_HOUSES,default=1"`
+	Devices  int    `env:"SST_MOCK_DEVICES,default=2"`
+	// Tick как часто показания датчиков приближаются к уставке, 0 - показания не меняются
+	Tick      time.Duration `env:"SST_MOCK_TICK,default=30s"`
+	Latency   time.Duration `env:"SST_MOCK_LATENCY,default=0s"`
+	ErrorRate float64       `env:"SST_MOCK_ERROR_RATE,default=0"`
+	TokenTTL  time.Duration `env:"SST_MOCK_TOKEN_TTL,default=0s"`
+}
+
+const shutdownTimeout = 5 * time.Second
+
+func main() {
+	var cfg config
+	if err := envdecode.StrictDecode(&cfg); err != nil {
+		zerolog.Fatal().Err(err).Msg("Cannot decode config envs")
+	}
+
+	logger, err := log.New(cfg.Logger)
+	if err != nil {
+		zerolog.Fatal().Err(err).Msg("Cannot init logger")
+	}
+
+	ctx, cancel := signal.NotifyContext(logger.WithContext(context.Background()), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	mock := ssttest.New()
+	if err := mock.Seed(cfg.EMail, cfg.Password, cfg.Houses, cfg.Devices); err != nil {
+		logger.Fatal().Err(err).Msg("Failed seed mock")
+	}
+	mock.SetFaults(ssttest.Faults{
+		Latency:   cfg.Latency,
+		ErrorRate: cfg.ErrorRate,
+		TokenTTL:  cfg.TokenTTL,
+	})
+
+	if cfg.Tick > 0 {
+		go func() {
+			ticker := time.NewTicker(cfg.Tick)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					mock.Step()
+				}
+			}
+		}()
+	}
+
+	srv := &http.Server{
+		Addr:    cfg.Address,
+		Handler: mock,
+	}
+	go func() {
+		<-ctx.Done()
+		sCtx, sCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer sCancel()
+		if err := srv.Shutdown(sCtx); err != nil {
+			logger.Error().Err(err).Msg("Failed shutdown server")
+		}
+	}()
+	logger.Info().Str("address", cfg.Address).Str("email", cfg.EMail).Msg("SST mock is listening")
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error().Err(err).Msg("Failed listen")
+		os.Exit(1)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"github.com/joeshaw/envdecode"
@@ -14,11 +15,19 @@ import (
 	"sstcloud-alice-gateway/internal/device_provider"
 	"sstcloud-alice-gateway/internal/device_provider/sst"
 	"sstcloud-alice-gateway/internal/device_provider/wrap_logger"
+	"sstcloud-alice-gateway/internal/i18n"
 	"sstcloud-alice-gateway/internal/log"
+	storageModels "sstcloud-alice-gateway/internal/models/storage"
+	"sstcloud-alice-gateway/internal/notifier"
 	"sstcloud-alice-gateway/internal/notifier/alice"
+	"sstcloud-alice-gateway/internal/notifier/broadcast"
+	"sstcloud-alice-gateway/internal/notifier/composite"
+	"sstcloud-alice-gateway/internal/notifier/mqtt"
+	"sstcloud-alice-gateway/internal/notifier/webhook"
 	"sstcloud-alice-gateway/internal/services"
 	"sstcloud-alice-gateway/internal/services/checker"
 	"sstcloud-alice-gateway/internal/services/rest"
+	"sstcloud-alice-gateway/internal/storage/backend"
 	"sstcloud-alice-gateway/internal/storage/sql"
 )
 
@@ -29,16 +38,53 @@ type config struct {
 	Storage  sql.Config
 	Checker  checker.Config
 	Notifier alice.Config
+	MQTT     mqtt.Config
+	Webhook  webhook.Config
+	// Notifiers список бэкендов уведомлений через ';': alice, mqtt, webhook
+	Notifiers []string `env:"NOTIFIERS,default=alice"`
+	// DeviceLayout представление термостатов по умолчанию: split или combined
+	DeviceLayout device_provider.Layout `env:"DEVICE_LAYOUT,default=split"`
+	// TemperatureRounding правила округления уставки через ';': <модель>=<nearest|down|up>
+	TemperatureRounding []string `env:"TEMPERATURE_ROUNDING"`
 }
 
-const signalChLen = 10
+const (
+	notifierAlice   = "alice"
+	notifierMQTT    = "mqtt"
+	notifierWebhook = "webhook"
+)
+
+const (
+	signalChLen = 10
+
+	commandMigrate = "migrate"
+)
 
 func main() {
+	if len(os.Args) > 1 {
+		if os.Args[1] != commandMigrate {
+			zerolog.Fatal().Str("command", os.Args[1]).Msg("Unknown command")
+		}
+		migrate()
+		return
+	}
+
 	var cfg config
 	if err := envdecode.StrictDecode(&cfg); err != nil {
 		zerolog.Fatal().Err(err).Msg("Cannot decode config envs")
 	}
 
+	if _, ok := device_provider.ParseLayout(string(cfg.DeviceLayout), device_provider.LayoutSplit); !ok {
+		zerolog.Fatal().Str("layout", string(cfg.DeviceLayout)).Msg("Unknown device layout")
+	}
+
+	rounding, err := sst.ParseRounding(cfg.TemperatureRounding)
+	if err != nil {
+		zerolog.Fatal().Err(err).Msg("Cannot parse temperature rounding")
+	}
+	cfg.SST.Rounding = rounding
+	sst.Register(cfg.SST)
+
 	logger, err := log.New(cfg.Logger)
 	if err != nil {
 		zerolog.Fatal().Err(err).Msg("Cannot init logger")
@@ -62,7 +108,10 @@ func main() {
 
 	orderRunner := services.OrderRunner{}
 
-	storage := sql.New(cfg.Storage)
+	storage, err := backend.New(cfg.Storage)
+	if err != nil {
+		logger.Panic().Err(err).Msg("Failed create storage")
+	}
 	if err := storage.Connect(ctx); err != nil {
 		logger.Panic().Err(err).Msg("Failed connect to db")
 	}
@@ -72,14 +121,67 @@ func main() {
 		}
 	}()
 
-	notifier := alice.New(cfg.Notifier)
-	checkerInstance := checker.New(cfg.Checker, storage, func(userID, linkID, email, password string) device_provider.DeviceProvider {
-		return wrap_logger.New(sst.New(sst.Config{Password: password, EMail: email, Config: cfg.SST.Config}), userID, linkID, storage)
+	broadcaster := broadcast.New()
+	var (
+		notifiers     = []notifier.Notifier{broadcaster}
+		notifierStats rest.NotifierStats
+		mqttCommands  interface{ HandleCommands(mqtt.DeviceProvider) }
+	)
+	for _, name := range cfg.Notifiers {
+		switch strings.TrimSpace(name) {
+		case notifierAlice:
+			if err := cfg.Notifier.Validate(); err != nil {
+				logger.Fatal().Err(err).Msg("Invalid alice notifier config")
+			}
+			aliceNotifier := alice.New(cfg.Notifier, storage)
+			if err := orderRunner.SetupService(ctx, aliceNotifier, "notifier_alice", g); err != nil {
+				logger.Fatal().Err(err).Msg("Failed setup alice notifier service")
+			}
+			notifiers = append(notifiers, aliceNotifier)
+			notifierStats = aliceNotifier
+		case notifierMQTT:
+			if err := cfg.MQTT.Validate(); err != nil {
+				logger.Fatal().Err(err).Msg("Invalid mqtt notifier config")
+			}
+			mqttNotifier := mqtt.New(cfg.MQTT)
+			if err := orderRunner.SetupService(ctx, mqttNotifier, "notifier_mqtt", g); err != nil {
+				logger.Fatal().Err(err).Msg("Failed setup mqtt notifier service")
+			}
+			notifiers = append(notifiers, mqttNotifier)
+			if cfg.MQTT.HADiscovery {
+				mqttCommands = mqttNotifier
+			}
+		case notifierWebhook:
+			webhookNotifier := webhook.New(cfg.Webhook, storage)
+			if err := orderRunner.SetupService(ctx, webhookNotifier, "notifier_webhook", g); err != nil {
+				logger.Fatal().Err(err).Msg("Failed setup webhook notifier service")
+			}
+			notifiers = append(notifiers, webhookNotifier)
+		default:
+			logger.Fatal().Str("notifier", name).Msg("Unknown notifier")
+		}
+	}
+	notifier := composite.New(notifiers...)
+	checkerInstance := checker.New(cfg.Checker, storage, func(link *storageModels.Link) (device_provider.DeviceProvider, error) {
+		language, _ := i18n.Parse(link.Language)
+		layout, _ := device_provider.ParseLayout(link.Layout, cfg.DeviceLayout)
+		provider, err := device_provider.New(link.ProviderName(), device_provider.Settings{
+			Credentials: link.ProviderCredentials(),
+			Language:    language,
+			Layout:      layout,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return wrap_logger.New(provider, link.UserID, link.ID, storage), nil
 	}, notifier)
+	if mqttCommands != nil {
+		mqttCommands.HandleCommands(checkerInstance)
+	}
 	if err := orderRunner.SetupService(ctx, checkerInstance, "checker", g); err != nil {
 		logger.Fatal().Err(err).Msg("Failed setup checker service")
 	}
-	restService, err := rest.New(ctx, cfg.Rest, logger.With().Str("role", "rest").Logger(), storage, checkerInstance)
+	restService, err := rest.New(ctx, cfg.Rest, logger.With().Str("role", "rest").Logger(), storage, checkerInstance, notifierStats, broadcaster)
 	if err != nil {
 		logger.Fatal().Err(err).Msg("Failed create rest service")
 	}
@@ -93,3 +195,37 @@ func main() {
 	}
 	logger.Info().Msg("The service is stopped")
 }
+
+type migrateConfig struct {
+	Logger  log.Config
+	Storage sql.Config
+}
+
+// migrate применяет миграции и завершается, для запуска отдельным шагом перед обновлением шлюза
+func migrate() {
+	var cfg migrateConfig
+	if err := envdecode.StrictDecode(&cfg); err != nil {
+		zerolog.Fatal().Err(err).Msg("Cannot decode config envs")
+	}
+	logger, err := log.New(cfg.Logger)
+	if err != nil {
+		zerolog.Fatal().Err(err).Msg("Cannot init logger")
+	}
+	ctx := logger.WithContext(context.Background())
+	cfg.Storage.AutoMigrate = false
+	storage, err := backend.New(cfg.Storage)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed create storage")
+	}
+	if err := storage.Connect(ctx); err != nil {
+		logger.Fatal().Err(err).Msg("Failed connect to db")
+	}
+	defer func() {
+		if err := storage.Disconnect(ctx); err != nil {
+			logger.Error().Err(err).Msg("Failed disconnect from db")
+		}
+	}()
+	if err := storage.Migrate(ctx); err != nil {
+		logger.Fatal().Err(err).Msg("Failed migrate db")
+	}
+}
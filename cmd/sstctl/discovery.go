@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+
+	"sstcloud-alice-gateway/internal/mappers"
+	"sstcloud-alice-gateway/internal/models/alice"
+)
+
+// runDiscovery печатает ответ на discovery Алисы, который шлюз отдал бы пользователю прямо сейчас
+func runDiscovery(ctx context.Context, cfg config, args []string) error {
+	flags := flag.NewFlagSet("discovery", flag.ContinueOnError)
+	userID := flags.String("user", "", "Alice user id")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *userID == "" {
+		return errUsage
+	}
+	if err := registerProviders(cfg); err != nil {
+		return err
+	}
+	db, closeDB, err := openStorage(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+	links, err := db.UserLinks(ctx, *userID)
+	if err != nil {
+		return err
+	}
+	result := alice.Devices{
+		UserID:  *userID,
+		Devices: []alice.Device{},
+	}
+	for _, link := range links {
+		logger := log.Ctx(ctx).With().Str("link_id", link.ID).Logger()
+		provider, err := linkProvider(cfg, link)
+		if err != nil {
+			return err
+		}
+		if err := provider.Init(ctx); err != nil {
+			return fmt.Errorf("link %s: %w", link.ID, err)
+		}
+		houses, err := provider.Houses(ctx)
+		if err != nil {
+			return fmt.Errorf("link %s: %w", link.ID, err)
+		}
+		for _, house := range houses {
+			devices, err := provider.Devices(ctx, house)
+			if err != nil {
+				return fmt.Errorf("link %s, house %d: %w", link.ID, house.ID, err)
+			}
+			logger.Debug().Int("house_id", house.ID).Int("devices", len(devices)).Msg("House loaded")
+			for _, device := range devices {
+				result.Devices = append(result.Devices, mappers.DeviceToAlice(device)...)
+			}
+		}
+	}
+	settings, err := db.DeviceSettings(ctx, *userID)
+	if err != nil {
+		return err
+	}
+	result.Devices = mappers.ApplySettings(result.Devices, settings)
+	return printJSON(alice.Response{
+		RequestID: "sstctl",
+		Payload:   result,
+	})
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joeshaw/envdecode"
+	"github.com/rs/zerolog/log"
+
+	"sstcloud-alice-gateway/internal/device_provider"
+	sstProvider "sstcloud-alice-gateway/internal/device_provider/sst"
+	"sstcloud-alice-gateway/internal/i18n"
+	storageModels "sstcloud-alice-gateway/internal/models/storage"
+	"sstcloud-alice-gateway/internal/storage"
+	"sstcloud-alice-gateway/internal/storage/backend"
+	"sstcloud-alice-gateway/internal/storage/sql"
+)
+
+// openStorage подключается к той же бд, что и шлюз, настройки берутся из DB_CONNECTION_STRING
+func openStorage(ctx context.Context) (storage.Storage, func(), error) {
+	var cfg sql.Config
+	if err := envdecode.StrictDecode(&cfg); err != nil {
+		return nil, nil, fmt.Errorf("cannot decode storage envs: %w", err)
+	}
+	db, err := backend.New(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := db.Connect(ctx); err != nil {
+		return nil, nil, err
+	}
+	return db, func() {
+		if err := db.Disconnect(ctx); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Failed disconnect from db")
+		}
+	}, nil
+}
+
+func findLink(ctx context.Context, db storage.Storage, linkID string) (*storageModels.Link, error) {
+	links, err := db.Links(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		if link.ID == linkID {
+			return link, nil
+		}
+	}
+	return nil, fmt.Errorf("link %s: %w", linkID, storage.ErrNotFound)
+}
+
+// linkCredentials учетные данные SST привязки
+func linkCredentials(link *storageModels.Link) (sstProvider.Credentials, error) {
+	var credentials sstProvider.Credentials
+	if link.ProviderName() != sstProvider.ProviderName {
+		return credentials, fmt.Errorf("link %s uses provider %q, only %q is supported", link.ID, link.ProviderName(), sstProvider.ProviderName)
+	}
+	if err := json.Unmarshal(link.ProviderCredentials(), &credentials); err != nil {
+		return credentials, err
+	}
+	return credentials, nil
+}
+
+func runLinks(ctx context.Context, cfg config, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch args[0] {
+	case "list":
+		return listLinks(ctx, args[1:])
+	case "add":
+		return addLink(ctx, cfg, args[1:])
+	case "remove":
+		return removeLink(ctx, args[1:])
+	}
+	return errUsage
+}
+
+func listLinks(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("links list", flag.ContinueOnError)
+	userID := flags.String("user", "", "only links of this Alice user")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	db, closeDB, err := openStorage(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	var links []*storageModels.Link
+	if *userID != "" {
+		links, err = db.UserLinks(ctx, *userID)
+	} else {
+		links, err = db.Links(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tUSER\tPROVIDER\tLOGIN\tLANGUAGE\tLAYOUT\tCREATED")
+	for _, link := range links {
+		login := "-"
+		if credentials, err := linkCredentials(link); err == nil {
+			login = credentials.EMail
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			link.ID, link.UserID, link.ProviderName(), login, orDash(link.Language), orDash(link.Layout), link.CreatedAt.Format(time.RFC3339))
+	}
+	return w.Flush()
+}
+
+func addLink(ctx context.Context, cfg config, args []string) error {
+	flags := flag.NewFlagSet("links add", flag.ContinueOnError)
+	userID := flags.String("user", "", "Alice user id")
+	email := flags.String("email", "", "SST email")
+	password := flags.String("password", "", "SST password")
+	language := flags.String("language", "", "language of names and errors: ru, en, kk")
+	layout := flags.String("layout", "", "thermostat layout: split or combined")
+	skipCheck := flags.Bool("skip-check", false, "do not verify credentials against SST")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *userID == "" || *email == "" || *password == "" {
+		return errUsage
+	}
+	if _, ok := i18n.Parse(*language); !ok {
+		return fmt.Errorf("unknown language %q", *language)
+	}
+	if _, ok := device_provider.ParseLayout(*layout, ""); !ok {
+		return fmt.Errorf("unknown layout %q", *layout)
+	}
+	credentials := sstProvider.Credentials{EMail: *email, Password: *password}
+	if !*skipCheck {
+		if err := login(ctx, cfg, credentials); err != nil {
+			return fmt.Errorf("credentials check failed: %w", err)
+		}
+	}
+	blob, err := json.Marshal(credentials)
+	if err != nil {
+		return err
+	}
+
+	db, closeDB, err := openStorage(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+	link := &storageModels.Link{
+		UserID:      *userID,
+		Provider:    sstProvider.ProviderName,
+		Credentials: string(blob),
+		Language:    *language,
+		Layout:      *layout,
+	}
+	if err := db.AddLink(ctx, link); err != nil {
+		return err
+	}
+	fmt.Println(link.ID)
+	return nil
+}
+
+func removeLink(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("links remove", flag.ContinueOnError)
+	linkID := flags.String("link", "", "link id")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *linkID == "" {
+		return errUsage
+	}
+	db, closeDB, err := openStorage(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+	if err := db.DeleteLink(ctx, *linkID); err != nil {
+		return fmt.Errorf("link %s: %w", *linkID, err)
+	}
+	return nil
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
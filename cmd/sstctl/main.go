@@ -0,0 +1,100 @@
+// sstctl утилита для дежурных: управление привязками, проверка учетных данных SST, просмотр домов и
+// устройств, установка температуры и эмуляция discovery Алисы без curl и SQL.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joeshaw/envdecode"
+	_ "github.com/joho/godotenv/autoload"
+	"github.com/rs/zerolog"
+
+	"sstcloud-alice-gateway/internal/device_provider"
+	"sstcloud-alice-gateway/pkg/sst"
+)
+
+type config struct {
+	SST sst.Config
+	// DeviceLayout представление термостатов по умолчанию, как у шлюза
+	DeviceLayout device_provider.Layout `env:"DEVICE_LAYOUT,default=split"`
+	// TemperatureRounding правила округления уставки через ';': <модель>=<nearest|down|up>
+	TemperatureRounding []string `env:"TEMPERATURE_ROUNDING"`
+	LoggerLevel         string   `env:"LOGGER_LEVEL,default=warn"`
+}
+
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, cfg config, args []string) error
+}
+
+var commands = []command{
+	{"links", "links list [-user id] | links add -user id -email e -password p [-language ru] [-layout split] | links remove -link id", runLinks},
+	{"check", "check -email e -password p | check -link id", runCheck},
+	{"houses", "houses -link id", runHouses},
+	{"set-temperature", "set-temperature -link id -house id -device id -value t", runSetTemperature},
+	{"discovery", "discovery -user id", runDiscovery},
+}
+
+var errUsage = errors.New("invalid arguments")
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	var cfg config
+	if err := envdecode.StrictDecode(&cfg); err != nil {
+		fail(fmt.Errorf("cannot decode config envs: %w", err))
+	}
+	level, err := zerolog.ParseLevel(cfg.LoggerLevel)
+	if err != nil {
+		fail(err)
+	}
+	// stdout занят результатом команды, логи уходят в stderr
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr}).Level(level).With().Timestamp().Logger()
+	ctx, cancel := signal.NotifyContext(logger.WithContext(context.Background()), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	for _, c := range commands {
+		if c.name != os.Args[1] {
+			continue
+		}
+		if err := c.run(ctx, cfg, os.Args[2:]); err != nil {
+			if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+				fmt.Fprintln(os.Stderr, "usage: sstctl "+c.usage)
+				os.Exit(2)
+			}
+			fail(err)
+		}
+		return
+	}
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: sstctl <command> [flags]")
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, c := range commands {
+		fmt.Fprintln(os.Stderr, "  "+c.usage)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "sstctl: "+err.Error())
+	os.Exit(1)
+}
+
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
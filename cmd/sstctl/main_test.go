@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestCommandsUsage неполные аргументы отклоняются до обращения к бд и SST
+func TestCommandsUsage(t *testing.T) {
+	tests := []struct {
+		command string
+		args    []string
+	}{
+		{command: "links"},
+		{command: "links", args: []string{"rename"}},
+		{command: "links", args: []string{"add", "-email", "e", "-password", "p"}},
+		{command: "links", args: []string{"remove"}},
+		{command: "check"},
+		{command: "check", args: []string{"-email", "e"}},
+		{command: "houses"},
+		{command: "set-temperature", args: []string{"-link", "link", "-house", "1", "-device", "2"}},
+		{command: "discovery"},
+	}
+	for _, tt := range tests {
+		var run func(ctx context.Context, cfg config, args []string) error
+		for _, c := range commands {
+			if c.name == tt.command {
+				run = c.run
+			}
+		}
+		if run == nil {
+			t.Fatalf("unknown command %q", tt.command)
+		}
+		if err := run(context.Background(), config{}, tt.args); !errors.Is(err, errUsage) {
+			t.Fatalf("%s %v: got %v, want usage error", tt.command, tt.args, err)
+		}
+	}
+}
+
+func TestAddLinkValidatesBeforeStorage(t *testing.T) {
+	for _, args := range [][]string{
+		{"add", "-user", "u", "-email", "e", "-password", "p", "-language", "de"},
+		{"add", "-user", "u", "-email", "e", "-password", "p", "-layout", "grid"},
+	} {
+		err := runLinks(context.Background(), config{}, args)
+		if err == nil || errors.Is(err, errUsage) {
+			t.Fatalf("%v: expected validation error, got %v", args, err)
+		}
+	}
+}
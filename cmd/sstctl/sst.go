@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"sstcloud-alice-gateway/internal/device_provider"
+	sstProvider "sstcloud-alice-gateway/internal/device_provider/sst"
+	"sstcloud-alice-gateway/internal/device_provider/wrap_logger"
+	"sstcloud-alice-gateway/internal/i18n"
+	storageModels "sstcloud-alice-gateway/internal/models/storage"
+	"sstcloud-alice-gateway/internal/profiles"
+	"sstcloud-alice-gateway/pkg/sst"
+)
+
+func login(ctx context.Context, cfg config, credentials sstProvider.Credentials) error {
+	_, err := sst.New(cfg.SST).Login(ctx, sst.LoginRequest{EMail: credentials.EMail, Password: credentials.Password})
+	return err
+}
+
+// registerProviders регистрирует провайдеров с общими настройками, как это делает шлюз
+func registerProviders(cfg config) error {
+	rounding, err := sstProvider.ParseRounding(cfg.TemperatureRounding)
+	if err != nil {
+		return err
+	}
+	sstProvider.Register(sstProvider.Config{Config: cfg.SST, Rounding: rounding})
+	return nil
+}
+
+// linkProvider провайдер привязки с ее языком и представлением, провайдеры должны быть зарегистрированы
+func linkProvider(cfg config, link *storageModels.Link) (device_provider.DeviceProvider, error) {
+	language, _ := i18n.Parse(link.Language)
+	layout, _ := device_provider.ParseLayout(link.Layout, cfg.DeviceLayout)
+	provider, err := device_provider.New(link.ProviderName(), device_provider.Settings{
+		Credentials: link.ProviderCredentials(),
+		Language:    language,
+		Layout:      layout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("link %s: %w", link.ID, err)
+	}
+	return provider, nil
+}
+
+// linkClient клиент SST, вошедший под учетными данными привязки
+func linkClient(ctx context.Context, cfg config, linkID string) (*sst.Client, error) {
+	db, closeDB, err := openStorage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer closeDB()
+	link, err := findLink(ctx, db, linkID)
+	if err != nil {
+		return nil, err
+	}
+	credentials, err := linkCredentials(link)
+	if err != nil {
+		return nil, err
+	}
+	client := sst.New(cfg.SST)
+	if _, err := client.Login(ctx, sst.LoginRequest{EMail: credentials.EMail, Password: credentials.Password}); err != nil {
+		return nil, fmt.Errorf("login: %w", err)
+	}
+	return client, nil
+}
+
+func runCheck(ctx context.Context, cfg config, args []string) error {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	email := flags.String("email", "", "SST email")
+	password := flags.String("password", "", "SST password")
+	linkID := flags.String("link", "", "check credentials of this link")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	switch {
+	case *linkID != "":
+		if _, err := linkClient(ctx, cfg, *linkID); err != nil {
+			return err
+		}
+	case *email != "" && *password != "":
+		if err := login(ctx, cfg, sstProvider.Credentials{EMail: *email, Password: *password}); err != nil {
+			return fmt.Errorf("login: %w", err)
+		}
+	default:
+		return errUsage
+	}
+	fmt.Println("ok")
+	return nil
+}
+
+type deviceDump struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	Model     string `json:"model"`
+	Supported bool   `json:"supported"`
+	Connected bool   `json:"connected"`
+	Enabled   *bool  `json:"enabled,omitempty"`
+	Setpoint  *int   `json:"setpoint,omitempty"`
+	Air       *int   `json:"air,omitempty"`
+	Floor     *int   `json:"floor,omitempty"`
+}
+
+type houseDump struct {
+	ID      int          `json:"id"`
+	Name    string       `json:"name"`
+	Devices []deviceDump `json:"devices"`
+}
+
+func runHouses(ctx context.Context, cfg config, args []string) error {
+	flags := flag.NewFlagSet("houses", flag.ContinueOnError)
+	linkID := flags.String("link", "", "link id")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *linkID == "" {
+		return errUsage
+	}
+	client, err := linkClient(ctx, cfg, *linkID)
+	if err != nil {
+		return err
+	}
+	houses, err := client.Houses(ctx)
+	if err != nil {
+		return err
+	}
+	result := make([]houseDump, 0, len(houses))
+	for _, house := range houses {
+		devices, err := client.Devices(ctx, house.ID)
+		if err != nil {
+			return fmt.Errorf("house %d: %w", house.ID, err)
+		}
+		dump := houseDump{ID: house.ID, Name: house.Name, Devices: make([]deviceDump, 0, len(devices))}
+		for _, device := range devices {
+			dump.Devices = append(dump.Devices, dumpDevice(device))
+		}
+		result = append(result, dump)
+	}
+	return printJSON(result)
+}
+
+func dumpDevice(device sst.Device) deviceDump {
+	_, supported := profiles.Lookup(device.Type.String())
+	result := deviceDump{
+		ID:        device.ID,
+		Name:      device.Name,
+		Model:     device.Type.String(),
+		Supported: supported,
+		Connected: device.IsConnected,
+	}
+	if parsed := device.TermParsedConfiguration; parsed != nil {
+		enabled := parsed.Settings.Status == sst.DeviceStatusOn
+		result.Enabled = &enabled
+		result.Setpoint = &parsed.Settings.TemperatureManual
+		result.Air = &parsed.CurrentTemperature.TemperatureAir
+		result.Floor = &parsed.CurrentTemperature.TemperatureFloor
+	}
+	return result
+}
+
+type setTemperatureArgs struct {
+	linkID   string
+	houseID  int
+	deviceID int
+	value    device_provider.Temperature
+}
+
+// parseSetTemperatureArgs разбирает аргументы set-temperature, уставка - как у Алисы и MQTT, с десятыми
+func parseSetTemperatureArgs(args []string) (setTemperatureArgs, error) {
+	var result setTemperatureArgs
+	flags := flag.NewFlagSet("set-temperature", flag.ContinueOnError)
+	flags.StringVar(&result.linkID, "link", "", "link id")
+	flags.IntVar(&result.houseID, "house", 0, "SST house id")
+	flags.IntVar(&result.deviceID, "device", 0, "SST device id")
+	value := flags.String("value", "", "setpoint in degrees, e.g. 25 or 24.5")
+	if err := flags.Parse(args); err != nil {
+		return result, err
+	}
+	if result.linkID == "" || result.houseID == 0 || result.deviceID == 0 || *value == "" || flags.NArg() != 0 {
+		return result, errUsage
+	}
+	temp, err := device_provider.ParseTemperature(*value)
+	if err != nil {
+		return result, fmt.Errorf("value %q: %w", *value, err)
+	}
+	result.value = temp
+	return result, nil
+}
+
+// runSetTemperature выставляет уставку через тот же провайдер, что и шлюз: с включением термостата,
+// округлением и проверкой диапазона модели и записью в журнал команд от имени администратора
+func runSetTemperature(ctx context.Context, cfg config, args []string) error {
+	parsed, err := parseSetTemperatureArgs(args)
+	if err != nil {
+		return err
+	}
+	if err := registerProviders(cfg); err != nil {
+		return err
+	}
+	db, closeDB, err := openStorage(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+	link, err := findLink(ctx, db, parsed.linkID)
+	if err != nil {
+		return err
+	}
+	provider, err := linkProvider(cfg, link)
+	if err != nil {
+		return err
+	}
+	provider = wrap_logger.New(provider, link.UserID, link.ID, db)
+	device, err := findDevice(ctx, provider, parsed.houseID, parsed.deviceID)
+	if err != nil {
+		return err
+	}
+	temp, ok := device.Limits.Normalize(parsed.value)
+	if !ok {
+		return fmt.Errorf("temperature %v is out of range %v-%v", temp, device.Limits.Min, device.Limits.Max)
+	}
+	requestID := uuid.NewString()
+	ctx = device_provider.WithCommandSource(ctx, string(storageModels.CommandOriginAdmin), requestID)
+	if err := provider.SetTemperature(ctx, device, temp); err != nil {
+		return err
+	}
+	fmt.Println("ok, request id " + requestID)
+	return nil
+}
+
+// findDevice поддерживаемое устройство дома привязки
+func findDevice(ctx context.Context, provider device_provider.DeviceProvider, houseID, deviceID int) (*device_provider.Device, error) {
+	houses, err := provider.Houses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, house := range houses {
+		if house.ID != houseID {
+			continue
+		}
+		devices, err := provider.Devices(ctx, house)
+		if err != nil {
+			return nil, err
+		}
+		for _, device := range devices {
+			if device.ID == deviceID {
+				return device, nil
+			}
+		}
+		return nil, fmt.Errorf("device %d not found in house %d or its model is not supported", deviceID, houseID)
+	}
+	return nil, fmt.Errorf("house %d not found", houseID)
+}
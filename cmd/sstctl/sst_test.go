@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"testing"
+
+	"sstcloud-alice-gateway/internal/device_provider"
+)
+
+func TestParseSetTemperatureArgs(t *testing.T) {
+	withValue := func(extra ...string) []string {
+		return append([]string{"-link", "link", "-house", "1", "-device", "2"}, extra...)
+	}
+	tests := []struct {
+		name    string
+		args    []string
+		want    device_provider.Temperature
+		wantErr bool
+		// errIs ожидаемая причина ошибки, если она важна
+		errIs error
+	}{
+		{name: "whole degrees", args: withValue("-value", "25"), want: device_provider.Degrees(25)},
+		{name: "tenths", args: withValue("-value", "24.5"), want: device_provider.Degrees(24) + 5},
+		{name: "rounded to tenths", args: withValue("-value", "24.56"), want: device_provider.Degrees(24) + 6},
+		{name: "zero is a value", args: withValue("-value", "0"), want: 0},
+		{name: "not a number", args: withValue("-value", "warm"), wantErr: true, errIs: device_provider.ErrInvalidTemperature},
+		{name: "no value", args: withValue(), wantErr: true, errIs: errUsage},
+		{name: "no link", args: []string{"-house", "1", "-device", "2", "-value", "25"}, wantErr: true, errIs: errUsage},
+		{name: "no house", args: []string{"-link", "link", "-device", "2", "-value", "25"}, wantErr: true, errIs: errUsage},
+		{name: "no device", args: []string{"-link", "link", "-house", "1", "-value", "25"}, wantErr: true, errIs: errUsage},
+		{name: "extra argument", args: withValue("-value", "25", "26"), wantErr: true, errIs: errUsage},
+		{name: "house is not a number", args: []string{"-link", "link", "-house", "x", "-device", "2", "-value", "25"}, wantErr: true},
+		{name: "help", args: []string{"-h"}, wantErr: true, errIs: flag.ErrHelp},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSetTemperatureArgs(tt.args)
+			if tt.wantErr {
+				if err == nil || (tt.errIs != nil && !errors.Is(err, tt.errIs)) {
+					t.Fatalf("got %+v, %v, want error %v", got, err, tt.errIs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := setTemperatureArgs{linkID: "link", houseID: 1, deviceID: 2, value: tt.want}
+			if got != want {
+				t.Fatalf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package device_provider
+
+type Changes uint
+
+const (
+	ChangedEnabled Changes = 1 << iota
+	ChangedConnected
+	ChangedSetDegreesFloor
+	ChangedDegreesFloor
+	ChangedDegreesAir
+
+	ChangedNone Changes = 0
+	ChangedAll          = ChangedEnabled | ChangedConnected | ChangedSetDegreesFloor | ChangedDegreesFloor | ChangedDegreesAir
+)
+
+func (c Changes) Has(o Changes) bool {
+	return c&o != 0
+}
+
+// Changes возвращает поля, которые отличаются от предыдущего состояния устройства.
+func (d *Device) Changes(prev *Device) Changes {
+	if prev == nil {
+		return ChangedAll
+	}
+	var result Changes
+	if d.Enabled != prev.Enabled {
+		result |= ChangedEnabled
+	}
+	if d.Connected != prev.Connected {
+		result |= ChangedConnected
+	}
+	if d.Tempometer.SetDegreesFloor != prev.Tempometer.SetDegreesFloor {
+		result |= ChangedSetDegreesFloor
+	}
+	if d.Tempometer.DegreesFloor != prev.Tempometer.DegreesFloor {
+		result |= ChangedDegreesFloor
+	}
+	if d.Tempometer.DegreesAir != prev.Tempometer.DegreesAir {
+		result |= ChangedDegreesAir
+	}
+	return result
+}
@@ -15,24 +15,25 @@ type Device struct {
 	Enabled          bool
 	Connected        bool
 	Tempometer       Tempometer
+	Limits           Limits
 	AdditionalFields map[string]string
 	UpdatedAt        time.Time
 }
 
 func (d *Device) String() string {
-	return fmt.Sprintf("%s (%s %s)", d.Name, d.Model, d.ID)
+	return fmt.Sprintf("%s (%s %s)", d.Name, d.Model, d.IDStr)
 }
 
 type Tempometer struct {
-	SetDegreesFloor          int
+	SetDegreesFloor          Temperature
 	ChangedAtSetDegreesFloor time.Time
-	DegreesFloor             int
+	DegreesFloor             Temperature
 	ChangedAtDegreesFloor    time.Time
-	DegreesAir               int
+	DegreesAir               Temperature
 	ChangedAtDegreesAir      time.Time
 }
 
-func (d *Device) SetTemperature(ctx context.Context, temp int) error {
+func (d *Device) SetTemperature(ctx context.Context, temp Temperature) error {
 	return d.House.DeviceProvider.SetTemperature(ctx, d, temp)
 }
 
@@ -0,0 +1,6 @@
+package device_provider
+
+import "errors"
+
+// ErrDeviceBusy предыдущая команда устройству еще выполняется
+var ErrDeviceBusy = errors.New("device busy")
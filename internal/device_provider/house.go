@@ -1,8 +1,12 @@
 package device_provider
 
+import "sstcloud-alice-gateway/internal/i18n"
+
 type House struct {
 	ID             int
 	Name           string
 	UserID         string
+	Language       i18n.Language
+	Layout         Layout
 	DeviceProvider DeviceProvider
 }
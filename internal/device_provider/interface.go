@@ -8,6 +8,11 @@ type DeviceProvider interface {
 	Init(ctx context.Context) error
 	Houses(ctx context.Context) ([]*House, error)
 	Devices(ctx context.Context, house *House) ([]*Device, error)
-	SetTemperature(ctx context.Context, device *Device, temp int) error
+	SetTemperature(ctx context.Context, device *Device, temp Temperature) error
 	PowerStatus(ctx context.Context, device *Device, power bool) error
 }
+
+// Invalidator реализуется провайдерами с кешем, чтобы принудительное обновление дома шло мимо кеша.
+type Invalidator interface {
+	Invalidate(house *House)
+}
@@ -0,0 +1,22 @@
+package device_provider
+
+// Layout способ представления термостата в Алисе
+type Layout string
+
+const (
+	// LayoutSplit термостат и отдельные датчики температуры воздуха и пола
+	LayoutSplit Layout = "split"
+	// LayoutCombined термостат с температурой пола и отдельный датчик температуры воздуха
+	LayoutCombined Layout = "combined"
+)
+
+// ParseLayout разбирает способ представления, пустая строка соответствует fallback
+func ParseLayout(s string, fallback Layout) (Layout, bool) {
+	switch Layout(s) {
+	case "":
+		return fallback, true
+	case LayoutSplit, LayoutCombined:
+		return Layout(s), true
+	}
+	return fallback, false
+}
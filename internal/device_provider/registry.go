@@ -0,0 +1,54 @@
+package device_provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"sstcloud-alice-gateway/internal/i18n"
+)
+
+// Settings параметры привязки, из которых фабрика создает провайдер
+type Settings struct {
+	// Credentials учетные данные в формате, который понимает провайдер
+	Credentials json.RawMessage
+	Language    i18n.Language
+	Layout      Layout
+}
+
+type Factory func(settings Settings) (DeviceProvider, error)
+
+var (
+	factories  = map[string]Factory{}
+	factoriesM sync.RWMutex
+)
+
+// Register регистрирует облако термостатов под именем, которое хранится в привязке
+func Register(name string, factory Factory) {
+	factoriesM.Lock()
+	defer factoriesM.Unlock()
+	factories[name] = factory
+}
+
+func New(name string, settings Settings) (DeviceProvider, error) {
+	factoriesM.RLock()
+	factory, exists := factories[name]
+	factoriesM.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("unknown device provider %q", name)
+	}
+	return factory(settings)
+}
+
+// Providers имена зарегистрированных провайдеров
+func Providers() []string {
+	factoriesM.RLock()
+	defer factoriesM.RUnlock()
+	result := make([]string, 0, len(factories))
+	for name := range factories {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,24 @@
+package device_provider
+
+import (
+	"context"
+)
+
+type sourceCtx struct{}
+
+type source struct {
+	origin    string
+	requestID string
+}
+
+// WithCommandSource помечает контекст источником команды для журнала аудита.
+func WithCommandSource(ctx context.Context, origin, requestID string) context.Context {
+	return context.WithValue(ctx, sourceCtx{}, source{origin: origin, requestID: requestID})
+}
+
+func CommandSource(ctx context.Context) (origin, requestID string) {
+	if s, ok := ctx.Value(sourceCtx{}).(source); ok {
+		return s.origin, s.requestID
+	}
+	return "", ""
+}
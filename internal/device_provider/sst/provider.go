@@ -2,12 +2,16 @@ package sst
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/rs/zerolog/log"
 
 	"sstcloud-alice-gateway/internal/device_provider"
+	"sstcloud-alice-gateway/internal/i18n"
+	"sstcloud-alice-gateway/internal/profiles"
 	"sstcloud-alice-gateway/pkg/sst"
 )
 
@@ -15,6 +19,24 @@ type Config struct {
 	sst.Config
 	Password string
 	EMail    string
+	Language i18n.Language
+	Layout   device_provider.Layout
+	// Rounding правило округления уставки по имени модели, по умолчанию RoundNearest
+	Rounding map[string]device_provider.Rounding
+}
+
+// ParseRounding разбирает список "<модель>=<nearest|down|up>"
+func ParseRounding(values []string) (map[string]device_provider.Rounding, error) {
+	result := make(map[string]device_provider.Rounding, len(values))
+	for _, value := range values {
+		model, rule, found := strings.Cut(value, "=")
+		rounding, ok := device_provider.ParseRounding(strings.TrimSpace(rule))
+		if !found || !ok {
+			return nil, fmt.Errorf("invalid rounding %q", value)
+		}
+		result[strings.TrimSpace(model)] = rounding
+	}
+	return result, nil
 }
 
 type Client struct {
@@ -34,13 +56,25 @@ func (c *Client) Init(ctx context.Context) error {
 		Username: c.config.EMail,
 		Password: c.config.Password,
 		EMail:    c.config.EMail,
-		Language: sst.LangRu,
+		Language: sstLanguage(c.config.Language),
 	})
 	return err
 }
 
+// sstLanguage SST поддерживает только русский и английский языки
+func sstLanguage(lang i18n.Language) sst.Language {
+	if lang == i18n.LangRu {
+		return sst.LangRu
+	}
+	return sst.LangEn
+}
+
 func (c *Client) Houses(ctx context.Context) ([]*device_provider.House, error) {
-	houses, err := c.cl.Houses(ctx)
+	var houses []sst.House
+	err := c.withAuth(ctx, func() (err error) {
+		houses, err = c.cl.Houses(ctx)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -49,6 +83,8 @@ func (c *Client) Houses(ctx context.Context) ([]*device_provider.House, error) {
 		result = append(result, &device_provider.House{
 			ID:             h.ID,
 			Name:           h.Name,
+			Language:       c.config.Language,
+			Layout:         c.config.Layout,
 			DeviceProvider: c,
 		})
 	}
@@ -56,32 +92,41 @@ func (c *Client) Houses(ctx context.Context) ([]*device_provider.House, error) {
 }
 
 func (c *Client) Devices(ctx context.Context, house *device_provider.House) ([]*device_provider.Device, error) {
-	devices, err := c.cl.Devices(ctx, house.ID)
+	var devices []sst.Device
+	err := c.withAuth(ctx, func() (err error) {
+		devices, err = c.cl.Devices(ctx, house.ID)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 	result := make([]*device_provider.Device, 0, len(devices))
 	now := time.Now()
 	for _, device := range devices {
-		device.Name = house.Name + " " + device.Name
-		if device.Type != sst.MCS350 && device.Type != sst.MCS300 {
+		profile, supported := profiles.Lookup(device.Type.String())
+		if !supported {
 			log.Ctx(ctx).Warn().Str("type", device.Type.String()).Str("name", device.Name).Msg("Not supported type")
 			continue
 		}
 
+		limits := profile.Limits
+		if rounding, exists := c.config.Rounding[device.Type.String()]; exists {
+			limits.Rounding = rounding
+		}
 		result = append(result, &device_provider.Device{
 			ID:    device.ID,
 			House: house,
 			IDStr: fmt.Sprintf("%d_%d", house.ID, device.ID),
-			Name:  house.Name + " " + device.Name,
+			Name:  device.Name,
 			Tempometer: device_provider.Tempometer{
-				DegreesFloor:             device.TermParsedConfiguration.CurrentTemperature.TemperatureFloor,
-				DegreesAir:               device.TermParsedConfiguration.CurrentTemperature.TemperatureAir,
-				SetDegreesFloor:          device.TermParsedConfiguration.Settings.TemperatureManual,
+				DegreesFloor:             device_provider.Degrees(device.TermParsedConfiguration.CurrentTemperature.TemperatureFloor),
+				DegreesAir:               device_provider.Degrees(device.TermParsedConfiguration.CurrentTemperature.TemperatureAir),
+				SetDegreesFloor:          device_provider.Degrees(device.TermParsedConfiguration.Settings.TemperatureManual),
 				ChangedAtDegreesFloor:    now,
 				ChangedAtDegreesAir:      now,
 				ChangedAtSetDegreesFloor: now,
 			},
+			Limits:    limits,
 			Model:     device.Type.String(),
 			Enabled:   device.TermParsedConfiguration.Settings.Status == sst.DeviceStatusOn,
 			Connected: device.IsConnected,
@@ -91,21 +136,37 @@ func (c *Client) Devices(ctx context.Context, house *device_provider.House) ([]*
 	return result, nil
 }
 
-func (c *Client) SetTemperature(ctx context.Context, device *device_provider.Device, temp int) error {
-	if err := c.cl.PowerStatus(ctx, device.House.ID, device.ID, true); err != nil {
-		return err
-	}
-	if err := c.cl.Temperature(ctx, device.House.ID, device.ID, temp); err != nil {
-		return err
+func (c *Client) SetTemperature(ctx context.Context, device *device_provider.Device, temp device_provider.Temperature) error {
+	// SST принимает уставку только в целых градусах. Вызывающие приводят ее к Limits.Precision, а молча
+	// отбросить десятые нельзя: в журнал команд попало бы не то значение, что ушло на устройство.
+	if temp != device_provider.Degrees(temp.Degrees()) {
+		return fmt.Errorf("%w: %s is not a whole degree", device_provider.ErrInvalidTemperature, temp)
 	}
-	return nil
+	return c.withAuth(ctx, func() error {
+		if err := c.cl.PowerStatus(ctx, device.House.ID, device.ID, true); err != nil {
+			return err
+		}
+		return c.cl.Temperature(ctx, device.House.ID, device.ID, temp.Degrees())
+	})
 }
 
 func (c *Client) PowerStatus(ctx context.Context, device *device_provider.Device, power bool) error {
-	if err := c.cl.PowerStatus(ctx, device.House.ID, device.ID, power); err != nil {
+	return c.withAuth(ctx, func() error {
+		return c.cl.PowerStatus(ctx, device.House.ID, device.ID, power)
+	})
+}
+
+// withAuth повторяет запрос один раз после повторного входа, если токен истек
+func (c *Client) withAuth(ctx context.Context, call func() error) error {
+	err := call()
+	if !errors.Is(err, sst.ErrUnauthorized) {
+		return err
+	}
+	log.Ctx(ctx).Info().Msg("Token expired, login again")
+	if err := c.Init(ctx); err != nil {
 		return err
 	}
-	return nil
+	return call()
 }
 
 func (c *Client) EMail() string {
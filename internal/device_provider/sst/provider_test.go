@@ -0,0 +1,101 @@
+package sst
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"sstcloud-alice-gateway/internal/device_provider"
+	"sstcloud-alice-gateway/pkg/sst"
+	"sstcloud-alice-gateway/pkg/sst/ssttest"
+)
+
+func TestProviderAgainstMock(t *testing.T) {
+	srv := ssttest.NewServer()
+	defer srv.Close()
+	srv.AddAccount("user@example.com", "secret")
+	houseID, _ := srv.AddHouse("user@example.com", "Дом")
+	deviceID, _ := srv.AddDevice(houseID, ssttest.Device{Name: "Пол", Type: sst.MCS300, Connected: true, Setpoint: 26, Air: 22, Floor: 24})
+	if _, err := srv.AddDevice(houseID, ssttest.Device{Name: "Счетчик", Type: sst.NeptunProWWiFi, Connected: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	provider := New(Config{Config: srv.Config(), EMail: "user@example.com", Password: "secret"})
+	if err := provider.Init(ctx); err != nil {
+		t.Fatal(err)
+	}
+	houses, err := provider.Houses(ctx)
+	if err != nil || len(houses) != 1 {
+		t.Fatalf("houses %v, err %v", houses, err)
+	}
+	devices, err := provider.Devices(ctx, houses[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(devices) != 1 {
+		t.Fatalf("expected only supported device, got %d", len(devices))
+	}
+	device := devices[0]
+	if device.ID != deviceID || device.Tempometer.SetDegreesFloor != device_provider.Degrees(26) || device.Tempometer.DegreesFloor != device_provider.Degrees(24) {
+		t.Fatalf("unexpected device %+v", device)
+	}
+
+	// после истечения токена провайдер входит заново и повторяет команду
+	srv.ExpireTokens()
+	if err := provider.SetTemperature(ctx, device, device_provider.Degrees(30)); err != nil {
+		t.Fatalf("set temperature after token expiry: %v", err)
+	}
+	state, _ := srv.Device(houseID, deviceID)
+	if state.Setpoint != 30 || !state.Enabled {
+		t.Fatalf("unexpected state %+v", state)
+	}
+
+	// десятые градуса SST не принимает, команда отклоняется целиком
+	if err := provider.SetTemperature(ctx, device, device_provider.Degrees(30)+5); !errors.Is(err, device_provider.ErrInvalidTemperature) {
+		t.Fatalf("expected ErrInvalidTemperature, got %v", err)
+	}
+	if state, _ := srv.Device(houseID, deviceID); state.Setpoint != 30 {
+		t.Fatalf("rejected setpoint reached device: %+v", state)
+	}
+}
+
+// TestRoundingByModelName правило из TEMPERATURE_ROUNDING применяется по имени модели, как в README
+func TestRoundingByModelName(t *testing.T) {
+	rounding, err := ParseRounding([]string{"MCS 350=down"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := ssttest.NewServer()
+	defer srv.Close()
+	srv.AddAccount("user@example.com", "secret")
+	houseID, _ := srv.AddHouse("user@example.com", "Дом")
+	for _, deviceType := range []sst.DeviceType{sst.MCS300, sst.MCS350} {
+		if _, err := srv.AddDevice(houseID, ssttest.Device{Name: deviceType.String(), Type: deviceType, Connected: true, Setpoint: 26}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := context.Background()
+	provider := New(Config{Config: srv.Config(), EMail: "user@example.com", Password: "secret", Rounding: rounding})
+	if err := provider.Init(ctx); err != nil {
+		t.Fatal(err)
+	}
+	houses, err := provider.Houses(ctx)
+	if err != nil || len(houses) != 1 {
+		t.Fatalf("houses %v, err %v", houses, err)
+	}
+	devices, err := provider.Devices(ctx, houses[0])
+	if err != nil || len(devices) != 2 {
+		t.Fatalf("devices %v, err %v", devices, err)
+	}
+	want := map[string]device_provider.Rounding{
+		sst.MCS300.String(): device_provider.RoundNearest,
+		sst.MCS350.String(): device_provider.RoundDown,
+	}
+	for _, device := range devices {
+		if device.Limits.Rounding != want[device.Model] {
+			t.Fatalf("%s: rounding %q, want %q", device.Model, device.Limits.Rounding, want[device.Model])
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package sst
+
+import (
+	"encoding/json"
+	"errors"
+
+	"sstcloud-alice-gateway/internal/device_provider"
+)
+
+const ProviderName = "sst"
+
+type Credentials struct {
+	EMail    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// Register регистрирует SST в реестре провайдеров, config содержит общие для всех привязок настройки
+func Register(config Config) {
+	device_provider.Register(ProviderName, func(settings device_provider.Settings) (device_provider.DeviceProvider, error) {
+		var credentials Credentials
+		if err := json.Unmarshal(settings.Credentials, &credentials); err != nil {
+			return nil, err
+		}
+		if credentials.EMail == "" || credentials.Password == "" {
+			return nil, errors.New("sst: email and password are required")
+		}
+		// копия общих настроек: фабрика вызывается для разных привязок одновременно
+		cfg := config
+		cfg.EMail = credentials.EMail
+		cfg.Password = credentials.Password
+		cfg.Language = settings.Language
+		cfg.Layout = settings.Layout
+		return New(cfg), nil
+	})
+}
@@ -0,0 +1,110 @@
+package device_provider
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// temperatureScale температура хранится в десятых долях градуса
+const temperatureScale = 10
+
+var ErrInvalidTemperature = errors.New("invalid temperature")
+
+// Temperature температура с фиксированной точкой, в десятых долях градуса
+type Temperature int64
+
+func Degrees(degrees int) Temperature {
+	return Temperature(degrees * temperatureScale)
+}
+
+// TemperatureFromFloat округляет значение до десятых
+func TemperatureFromFloat(value float64) (Temperature, error) {
+	scaled := math.Round(value * temperatureScale)
+	if math.IsNaN(scaled) || scaled > math.MaxInt32 || scaled < math.MinInt32 {
+		return 0, ErrInvalidTemperature
+	}
+	return Temperature(scaled), nil
+}
+
+func ParseTemperature(s string) (Temperature, error) {
+	value, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, ErrInvalidTemperature
+	}
+	return TemperatureFromFloat(value)
+}
+
+func (t Temperature) Float() float64 {
+	return float64(t) / temperatureScale
+}
+
+// Degrees целые градусы с округлением к ближайшему
+func (t Temperature) Degrees() int {
+	return int(math.Round(t.Float()))
+}
+
+func (t Temperature) String() string {
+	return strconv.FormatFloat(t.Float(), 'f', -1, 64)
+}
+
+func (t Temperature) MarshalJSON() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *Temperature) UnmarshalJSON(data []byte) error {
+	value, err := ParseTemperature(string(data))
+	if err != nil {
+		return err
+	}
+	*t = value
+	return nil
+}
+
+type Rounding string
+
+const (
+	RoundNearest Rounding = "nearest"
+	RoundDown    Rounding = "down"
+	RoundUp      Rounding = "up"
+)
+
+func ParseRounding(s string) (Rounding, bool) {
+	switch Rounding(s) {
+	case RoundNearest, RoundDown, RoundUp:
+		return Rounding(s), true
+	}
+	return "", false
+}
+
+// Round приводит температуру к шагу step
+func (t Temperature) Round(step Temperature, rounding Rounding) Temperature {
+	if step <= 0 {
+		return t
+	}
+	value := float64(t) / float64(step)
+	switch rounding {
+	case RoundDown:
+		value = math.Floor(value)
+	case RoundUp:
+		value = math.Ceil(value)
+	default:
+		value = math.Round(value)
+	}
+	return Temperature(value) * step
+}
+
+// Limits допустимый диапазон уставки модели
+type Limits struct {
+	Min       Temperature
+	Max       Temperature
+	Precision Temperature
+	Rounding  Rounding
+}
+
+// Normalize округляет уставку по правилам модели и проверяет диапазон
+func (l Limits) Normalize(t Temperature) (Temperature, bool) {
+	t = t.Round(l.Precision, l.Rounding)
+	return t, t >= l.Min && t <= l.Max
+}
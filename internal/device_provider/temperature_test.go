@@ -0,0 +1,135 @@
+package device_provider
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTemperatureFromFloat(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   float64
+		want    Temperature
+		wantErr bool
+	}{
+		{name: "whole", value: 25, want: 250},
+		{name: "tenths", value: 21.5, want: 215},
+		{name: "rounds to tenths", value: 21.46, want: 215},
+		{name: "negative", value: -2.5, want: -25},
+		{name: "max", value: math.MaxInt32 / temperatureScale, want: math.MaxInt32 / temperatureScale * temperatureScale},
+		{name: "too large", value: math.MaxInt32, wantErr: true},
+		{name: "too small", value: math.MinInt32, wantErr: true},
+		{name: "nan", value: math.NaN(), wantErr: true},
+		{name: "inf", value: math.Inf(1), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TemperatureFromFloat(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRounding(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   Rounding
+		wantOk bool
+	}{
+		{value: "nearest", want: RoundNearest, wantOk: true},
+		{value: "down", want: RoundDown, wantOk: true},
+		{value: "up", want: RoundUp, wantOk: true},
+		{value: ""},
+		{value: "Down"},
+		{value: "floor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := ParseRounding(tt.value)
+			if got != tt.want || ok != tt.wantOk {
+				t.Fatalf("got %q, %v, want %q, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestTemperatureRound(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    Temperature
+		step     Temperature
+		rounding Rounding
+		want     Temperature
+	}{
+		{name: "nearest down", value: 214, step: Degrees(1), rounding: RoundNearest, want: 210},
+		{name: "nearest half up", value: 215, step: Degrees(1), rounding: RoundNearest, want: 220},
+		{name: "nearest by default", value: 216, step: Degrees(1), want: 220},
+		{name: "down", value: 219, step: Degrees(1), rounding: RoundDown, want: 210},
+		{name: "up", value: 211, step: Degrees(1), rounding: RoundUp, want: 220},
+		{name: "exact step", value: 220, step: Degrees(1), rounding: RoundUp, want: 220},
+		{name: "half degree step", value: 213, step: 5, rounding: RoundNearest, want: 215},
+		{name: "negative down", value: -11, step: Degrees(1), rounding: RoundDown, want: -20},
+		{name: "negative up", value: -11, step: Degrees(1), rounding: RoundUp, want: -10},
+		{name: "zero step", value: 213, step: 0, rounding: RoundUp, want: 213},
+		{name: "negative step", value: 213, step: -10, rounding: RoundUp, want: 213},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.value.Round(tt.step, tt.rounding); got != tt.want {
+				t.Fatalf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimitsNormalize(t *testing.T) {
+	limits := Limits{
+		Min:       Degrees(12),
+		Max:       Degrees(45),
+		Precision: Degrees(1),
+		Rounding:  RoundNearest,
+	}
+	tests := []struct {
+		name     string
+		value    Temperature
+		rounding Rounding
+		want     Temperature
+		wantOk   bool
+	}{
+		{name: "inside", value: Degrees(25), want: Degrees(25), wantOk: true},
+		{name: "rounded", value: 254, want: Degrees(25), wantOk: true},
+		{name: "min", value: Degrees(12), want: Degrees(12), wantOk: true},
+		{name: "max", value: Degrees(45), want: Degrees(45), wantOk: true},
+		{name: "rounded up to min", value: 115, want: Degrees(12), wantOk: true},
+		{name: "rounded down to max", value: 454, want: Degrees(45), wantOk: true},
+		{name: "below min", value: 114, want: Degrees(11)},
+		{name: "above max", value: 455, want: Degrees(46)},
+		{name: "down keeps max", value: 459, rounding: RoundDown, want: Degrees(45), wantOk: true},
+		{name: "up leaves range", value: 451, rounding: RoundUp, want: Degrees(46)},
+		{name: "down leaves range", value: 119, rounding: RoundDown, want: Degrees(11)},
+		{name: "up keeps min", value: 111, rounding: RoundUp, want: Degrees(12), wantOk: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limits := limits
+			if tt.rounding != "" {
+				limits.Rounding = tt.rounding
+			}
+			got, ok := limits.Normalize(tt.value)
+			if got != tt.want || ok != tt.wantOk {
+				t.Fatalf("got %d, %v, want %d, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
@@ -13,7 +13,8 @@ import (
 )
 
 const (
-	cacheDuration  = time.Minute * 5
+	// кеш лишь схлопывает близкие по времени запросы, частотой опроса управляет checker
+	cacheDuration  = time.Second * 10
 	cacheKeyHouses = "house"
 )
 
@@ -26,10 +27,13 @@ type wrapper struct {
 	isInitM sync.Mutex
 	callM   sync.Mutex
 	cache   *cache.Cache
+	busy    map[string]struct{}
+	busyM   sync.Mutex
 }
 
 type Logger interface {
 	Log(ctx context.Context, linkID string, level storage.LogLevel, msg string)
+	AddCommand(ctx context.Context, command *storage.Command) error
 }
 
 func New(child device_provider.DeviceProvider, userID, linkID string, logger Logger) device_provider.DeviceProvider {
@@ -39,9 +43,27 @@ func New(child device_provider.DeviceProvider, userID, linkID string, logger Log
 		linkID: linkID,
 		userID: userID,
 		cache:  cache.New(cacheDuration, cacheDuration/2),
+		busy:   map[string]struct{}{},
 	}
 }
 
+// acquire не дает отправить устройству новую команду, пока не завершилась предыдущая
+func (w *wrapper) acquire(device *device_provider.Device) bool {
+	w.busyM.Lock()
+	defer w.busyM.Unlock()
+	if _, exists := w.busy[device.IDStr]; exists {
+		return false
+	}
+	w.busy[device.IDStr] = struct{}{}
+	return true
+}
+
+func (w *wrapper) release(device *device_provider.Device) {
+	w.busyM.Lock()
+	defer w.busyM.Unlock()
+	delete(w.busy, device.IDStr)
+}
+
 func (w *wrapper) insure(ctx context.Context) error {
 	w.isInitM.Lock()
 	defer w.isInitM.Unlock()
@@ -86,12 +108,20 @@ func (w *wrapper) Houses(ctx context.Context) ([]*device_provider.House, error)
 	w.cache.Set(cacheKey, result, cache.DefaultExpiration)
 	return result, nil
 }
+func (w *wrapper) Invalidate(house *device_provider.House) {
+	w.cache.Delete(devicesCacheKey(house))
+}
+
+func devicesCacheKey(house *device_provider.House) string {
+	return cacheKeyHouses + strconv.Itoa(house.ID)
+}
+
 func (w *wrapper) Devices(ctx context.Context, house *device_provider.House) ([]*device_provider.Device, error) {
-	cacheKey := cacheKeyHouses + strconv.Itoa(house.ID)
+	cacheKey := devicesCacheKey(house)
 	{
 		obj, exists := w.cache.Get(cacheKey)
 		if exists {
-			return obj.([]*device_provider.Device), nil
+			return copyDevices(obj.([]*device_provider.Device)), nil
 		}
 	}
 	w.callM.Lock()
@@ -106,29 +136,85 @@ func (w *wrapper) Devices(ctx context.Context, house *device_provider.House) ([]
 	}
 	w.logger.Log(ctx, w.linkID, storage.Info, "Success get devices. Total "+strconv.Itoa(len(result)))
 	w.cache.Set(cacheKey, result, cache.DefaultExpiration)
-	return result, nil
+	return copyDevices(result), nil
+}
+
+// copyDevices копии закэшированных устройств: опрашивающий их checker меняет поля, пока прежний
+// ответ еще читают обработчики запросов
+func copyDevices(devices []*device_provider.Device) []*device_provider.Device {
+	result := make([]*device_provider.Device, 0, len(devices))
+	for _, device := range devices {
+		d := *device
+		result = append(result, &d)
+	}
+	return result
 }
 
-func (w *wrapper) SetTemperature(ctx context.Context, device *device_provider.Device, temp int) error {
+func (w *wrapper) SetTemperature(ctx context.Context, device *device_provider.Device, temp device_provider.Temperature) error {
+	if !w.acquire(device) {
+		w.audit(ctx, device, storage.CommandTemperature, device.Tempometer.SetDegreesFloor.String(), temp.String(), device_provider.ErrDeviceBusy)
+		return device_provider.ErrDeviceBusy
+	}
+	defer w.release(device)
 	if err := w.insure(ctx); err != nil {
+		w.audit(ctx, device, storage.CommandTemperature, device.Tempometer.SetDegreesFloor.String(), temp.String(), err)
 		return err
 	}
-	if err := w.child.SetTemperature(ctx, device, temp); err != nil {
+	err := w.child.SetTemperature(ctx, device, temp)
+	w.audit(ctx, device, storage.CommandTemperature, device.Tempometer.SetDegreesFloor.String(), temp.String(), err)
+	if err != nil {
 		w.logger.Log(ctx, w.linkID, storage.Error, "Failed set temp: "+err.Error())
 		return err
 	}
-	w.logger.Log(ctx, w.linkID, storage.Info, "Success set temp on device "+device.String()+" to "+strconv.Itoa(temp))
+	w.Invalidate(device.House)
+	w.logger.Log(ctx, w.linkID, storage.Info, "Success set temp on device "+device.String()+" to "+temp.String())
 	return nil
 }
 
 func (w *wrapper) PowerStatus(ctx context.Context, device *device_provider.Device, power bool) error {
+	if !w.acquire(device) {
+		w.audit(ctx, device, storage.CommandPower, strconv.FormatBool(device.Enabled), strconv.FormatBool(power), device_provider.ErrDeviceBusy)
+		return device_provider.ErrDeviceBusy
+	}
+	defer w.release(device)
 	if err := w.insure(ctx); err != nil {
+		w.audit(ctx, device, storage.CommandPower, strconv.FormatBool(device.Enabled), strconv.FormatBool(power), err)
 		return err
 	}
-	if err := w.child.PowerStatus(ctx, device, power); err != nil {
+	err := w.child.PowerStatus(ctx, device, power)
+	w.audit(ctx, device, storage.CommandPower, strconv.FormatBool(device.Enabled), strconv.FormatBool(power), err)
+	if err != nil {
 		w.logger.Log(ctx, w.linkID, storage.Error, "Failed set power status: "+err.Error())
 		return err
 	}
+	w.Invalidate(device.House)
 	w.logger.Log(ctx, w.linkID, storage.Info, "Success set power status on device "+device.String()+" to "+strconv.FormatBool(power))
 	return nil
 }
+
+func (w *wrapper) audit(ctx context.Context, device *device_provider.Device, name storage.CommandName, oldValue, newValue string, err error) {
+	origin, requestID := device_provider.CommandSource(ctx)
+	if origin == "" {
+		origin = string(storage.CommandOriginUnknown)
+	}
+	command := storage.Command{
+		UserID:     w.userID,
+		LinkID:     &w.linkID,
+		DeviceID:   device.IDStr,
+		DeviceName: device.Name,
+		Command:    name,
+		OldValue:   oldValue,
+		NewValue:   newValue,
+		Origin:     storage.CommandOrigin(origin),
+		RequestID:  requestID,
+		Status:     storage.CommandStatusSuccess,
+		Time:       time.Now().UTC(),
+	}
+	if err != nil {
+		command.Status = storage.CommandStatusError
+		command.Error = err.Error()
+	}
+	if err := w.logger.AddCommand(ctx, &command); err != nil {
+		w.logger.Log(ctx, w.linkID, storage.Error, "Failed audit command: "+err.Error())
+	}
+}
@@ -0,0 +1,301 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"sstcloud-alice-gateway/internal/mappers"
+	"sstcloud-alice-gateway/internal/models/alice"
+	storageModels "sstcloud-alice-gateway/internal/models/storage"
+	"sstcloud-alice-gateway/internal/storage"
+	"sstcloud-alice-gateway/pkg/sst"
+	"sstcloud-alice-gateway/pkg/sst/ssttest"
+)
+
+const (
+	userID   = "alice-user"
+	email    = "user@example.com"
+	password = "secret"
+)
+
+// setup поднимает шлюз с одним домом и термостатом и ждет, пока Яндекс увидит его вместе с датчиками
+func setup(t *testing.T) (*gateway, mappers.DeviceID) {
+	t.Helper()
+	g := newGateway(t)
+	g.sst.AddAccount(email, password)
+	houseID, err := g.sst.AddHouse(email, "Дача")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deviceID, err := g.sst.AddDevice(houseID, ssttest.Device{
+		Name:      "Кухня",
+		Type:      sst.MCS350,
+		Connected: true,
+		Setpoint:  25,
+		Air:       21,
+		Floor:     23,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.addLink(userID, email, password)
+	g.start()
+	g.waitDevices(userID, 3)
+	return g, mappers.DeviceID{HouseID: houseID, DeviceID: deviceID}
+}
+
+func TestDiscovery(t *testing.T) {
+	g, id := setup(t)
+	devices := g.discovery(userID)
+	byID := map[string]alice.Device{}
+	for _, device := range devices.Devices {
+		byID[device.ID] = device
+	}
+	thermostat, exists := byID[id.String()]
+	if !exists {
+		t.Fatalf("thermostat %s is not discovered: %+v", id, devices.Devices)
+	}
+	if thermostat.Type != alice.DeviceTypeThermostat || thermostat.Name != "Кухня" || thermostat.Room != "Дача" {
+		t.Errorf("unexpected thermostat %+v", thermostat)
+	}
+	for _, sensor := range []string{mappers.AdditionalSensorAir, mappers.AdditionalSensorFloor} {
+		device, exists := byID[id.WithSensor(sensor).String()]
+		if !exists || device.Type != alice.DeviceTypeSensor {
+			t.Errorf("sensor %s is not discovered", sensor)
+		}
+	}
+	if devices := g.discovery("stranger"); len(devices.Devices) != 0 {
+		t.Errorf("stranger sees devices %+v", devices.Devices)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	g, id := setup(t)
+	devices := g.query(userID, id.String(), id.WithSensor(mappers.AdditionalSensorAir).String(), "1_999")
+	if len(devices.Devices) != 3 {
+		t.Fatalf("expected 3 devices, got %+v", devices.Devices)
+	}
+	thermostat := devices.Devices[0]
+	if on := capabilityValue(t, thermostat.Capabilities, alice.CapabilityTypeOnOff); on != false {
+		t.Errorf("expected thermostat off, got %v", on)
+	}
+	if temp := capabilityValue(t, thermostat.Capabilities, alice.CapabilityTypeRange); temp != 25.0 {
+		t.Errorf("expected setpoint 25, got %v", temp)
+	}
+	air := devices.Devices[1]
+	if len(air.Properties) != 1 || air.Properties[0].State.Value != 21.0 {
+		t.Errorf("unexpected air sensor %+v", air)
+	}
+	if missing := devices.Devices[2]; missing.ErrorCode != alice.ErrorCodeDeviceNotFound {
+		t.Errorf("expected DEVICE_NOT_FOUND, got %+v", missing)
+	}
+}
+
+func TestActionSendsStateCallback(t *testing.T) {
+	g, id := setup(t)
+	devices := g.action(userID, alice.DeviceRequest{
+		ID: id.String(),
+		Capabilities: []alice.CapabilityRequest{
+			capabilityRequest(alice.CapabilityTypeOnOff, "on", true),
+			capabilityRequest(alice.CapabilityTypeRange, "temperature", 30),
+		},
+	}, alice.DeviceRequest{ID: "1_999"})
+	if len(devices.Devices) != 2 {
+		t.Fatalf("expected 2 devices, got %+v", devices.Devices)
+	}
+	for _, capability := range devices.Devices[0].Capabilities {
+		result := capability.(map[string]interface{})["state"].(map[string]interface{})["action_result"].(map[string]interface{})
+		if result["status"] != string(alice.ActionResultStatusDone) {
+			t.Errorf("capability is not applied: %v", capability)
+		}
+	}
+	if result := devices.Devices[1].ActionResult; result == nil || result.ErrorCode != alice.ErrorCodeDeviceNotFound {
+		t.Errorf("expected DEVICE_NOT_FOUND, got %+v", devices.Devices[1])
+	}
+
+	state, err := g.sst.Device(id.HouseID, id.DeviceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !state.Enabled || state.Setpoint != 30 {
+		t.Errorf("SST state is not changed: %+v", state)
+	}
+
+	callback, err := g.yandex.WaitState(callbackTTL, func(user string, device alice.PayloadStateDevice) bool {
+		return user == userID && device.ID == id.String() && stateValue(device, alice.CapabilityTypeRange) == 30.0
+	})
+	if err != nil {
+		t.Fatalf("no state callback: %v, received %+v", err, g.yandex.States())
+	}
+	if on := stateValue(callback, alice.CapabilityTypeOnOff); on != true {
+		t.Errorf("expected on in callback, got %v", on)
+	}
+
+	commands, err := g.storage.Commands(context.Background(), storage.CommandsFilter{UserID: userID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commands) != 2 {
+		t.Fatalf("expected 2 audited commands, got %d", len(commands))
+	}
+	for _, command := range commands {
+		if command.Origin != storageModels.CommandOriginAlice || command.Status != storageModels.CommandStatusSuccess || command.RequestID != requestID {
+			t.Errorf("unexpected command %+v", command)
+		}
+	}
+}
+
+func TestCloudChangeSendsStateCallback(t *testing.T) {
+	g, id := setup(t)
+	// первый callback Яндекс отвергает, шлюз должен повторить отправку
+	g.yandex.FailNext(1)
+	if err := g.sst.UpdateDevice(id.HouseID, id.DeviceID, func(device *ssttest.Device) {
+		device.Floor = 27
+	}); err != nil {
+		t.Fatal(err)
+	}
+	g.refresh(userID, id.HouseID)
+	floorID := id.WithSensor(mappers.AdditionalSensorFloor).String()
+	if _, err := g.yandex.WaitState(callbackTTL, func(user string, device alice.PayloadStateDevice) bool {
+		return user == userID && device.ID == floorID && len(device.Properties) == 1 && device.Properties[0].State.Value == 27.0
+	}); err != nil {
+		t.Fatalf("no floor callback: %v, received %+v", err, g.yandex.States())
+	}
+}
+
+func TestNewDeviceSendsDiscoveryCallback(t *testing.T) {
+	g, id := setup(t)
+	if _, err := g.sst.AddDevice(id.HouseID, ssttest.Device{Name: "Ванная", Type: sst.MCS300, Connected: true, Setpoint: 28}); err != nil {
+		t.Fatal(err)
+	}
+	g.refresh(userID, id.HouseID)
+	if err := g.yandex.WaitDiscovery(callbackTTL, userID); err != nil {
+		t.Fatalf("no discovery callback: %v", err)
+	}
+	g.waitDevices(userID, 6)
+}
+
+func TestLanguageChangeSendsDiscoveryCallback(t *testing.T) {
+	g, id := setup(t)
+	links, err := g.storage.UserLinks(context.Background(), userID)
+	if err != nil || len(links) != 1 {
+		t.Fatalf("expected one link, got %d (%v)", len(links), err)
+	}
+	if discoveries := g.yandex.Discoveries(); len(discoveries) != 0 {
+		t.Fatalf("unexpected discovery callbacks before change: %+v", discoveries)
+	}
+	g.do(http.MethodPut, "/api/v1/links/"+links[0].ID+"/language", userID, map[string]string{"language": "en"}, http.StatusNoContent).Body.Close()
+	if err := g.yandex.WaitDiscovery(callbackTTL, userID); err != nil {
+		t.Fatalf("no discovery callback: %v", err)
+	}
+	// discovery после callback'а уже отдает имена на новом языке
+	airID := id.WithSensor(mappers.AdditionalSensorAir).String()
+	for _, device := range g.discovery(userID).Devices {
+		if device.ID == airID && !strings.HasSuffix(device.Name, "air temperature") {
+			t.Fatalf("sensor name is not translated: %q", device.Name)
+		}
+	}
+}
+
+func TestDeviceSettingSendsDiscoveryCallback(t *testing.T) {
+	g, id := setup(t)
+	if discoveries := g.yandex.Discoveries(); len(discoveries) != 0 {
+		t.Fatalf("unexpected discovery callbacks before change: %+v", discoveries)
+	}
+	path := "/api/v1/devices/" + id.String() + "/settings"
+	g.do(http.MethodPut, path, userID, map[string]interface{}{"name": "Спальня"}, http.StatusOK).Body.Close()
+	if err := g.yandex.WaitDiscovery(callbackTTL, userID); err != nil {
+		t.Fatalf("no discovery callback after save: %v", err)
+	}
+	for _, device := range g.discovery(userID).Devices {
+		if device.ID == id.String() && device.Name != "Спальня" {
+			t.Fatalf("setting is not applied: %q", device.Name)
+		}
+	}
+	g.do(http.MethodDelete, path, userID, nil, http.StatusNoContent).Body.Close()
+	eventually(t, func() bool {
+		return len(g.yandex.Discoveries()) == 2
+	})
+}
+
+func TestCombinedLayout(t *testing.T) {
+	g, id := setup(t)
+	links, err := g.storage.UserLinks(context.Background(), userID)
+	if err != nil || len(links) != 1 {
+		t.Fatalf("expected one link, got %d (%v)", len(links), err)
+	}
+	g.do(http.MethodPut, "/api/v1/links/"+links[0].ID+"/layout", userID, map[string]string{"device_layout": "combined"}, http.StatusNoContent).Body.Close()
+	if err := g.yandex.WaitDiscovery(callbackTTL, userID); err != nil {
+		t.Fatalf("no discovery callback: %v", err)
+	}
+	airID := id.WithSensor(mappers.AdditionalSensorAir).String()
+	devices := g.discovery(userID).Devices
+	if len(devices) != 2 || devices[0].ID != id.String() || devices[1].ID != airID {
+		t.Fatalf("expected thermostat and air sensor, got %+v", devices)
+	}
+	if props := devices[0].Properties; len(props) != 1 || props[0].Parameters.Instance != alice.PropertyParameterInstanceTemperature {
+		t.Fatalf("expected single temperature property, got %+v", props)
+	}
+	// идентификатор датчика пола, запомненный Алисой до переключения, продолжает отвечать
+	floorID := id.WithSensor(mappers.AdditionalSensorFloor).String()
+	if queried := g.query(userID, floorID).Devices; len(queried) != 1 || queried[0].ErrorCode != "" {
+		t.Fatalf("legacy sensor id is not answered: %+v", queried)
+	}
+}
+
+func TestOfflineDevice(t *testing.T) {
+	g, id := setup(t)
+	if err := g.sst.UpdateDevice(id.HouseID, id.DeviceID, func(device *ssttest.Device) {
+		device.Connected = false
+	}); err != nil {
+		t.Fatal(err)
+	}
+	g.refresh(userID, id.HouseID)
+	eventually(t, func() bool {
+		devices := g.query(userID, id.String())
+		return devices.Devices[0].ErrorCode == alice.ErrorCodeDeviceUnreachable
+	})
+	devices := g.action(userID, alice.DeviceRequest{
+		ID:           id.String(),
+		Capabilities: []alice.CapabilityRequest{capabilityRequest(alice.CapabilityTypeOnOff, "on", true)},
+	})
+	if result := devices.Devices[0].ActionResult; result == nil || result.ErrorCode != alice.ErrorCodeDeviceUnreachable {
+		t.Errorf("expected DEVICE_UNREACHABLE, got %+v", devices.Devices[0])
+	}
+}
+
+func capabilityRequest(capabilityType alice.CapabilityType, instance string, value interface{}) alice.CapabilityRequest {
+	var request alice.CapabilityRequest
+	request.Type = capabilityType
+	request.State.Instance = instance
+	request.State.Value, _ = json.Marshal(value)
+	return request
+}
+
+// capabilityValue значение умения из ответа на query, умения приходят как произвольный json
+func capabilityValue(t *testing.T, capabilities []interface{}, capabilityType alice.CapabilityType) interface{} {
+	t.Helper()
+	for _, capability := range capabilities {
+		fields := capability.(map[string]interface{})
+		if fields["type"] == string(capabilityType) {
+			return fields["state"].(map[string]interface{})["value"]
+		}
+	}
+	t.Fatalf("capability %s not found in %v", capabilityType, capabilities)
+	return nil
+}
+
+func stateValue(device alice.PayloadStateDevice, capabilityType alice.CapabilityType) interface{} {
+	for _, capability := range device.Capabilities {
+		if capability.Type != capabilityType {
+			continue
+		}
+		if state, ok := capability.State.(map[string]interface{}); ok {
+			return state["value"]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/oklog/run"
+	"github.com/rs/zerolog"
+
+	"sstcloud-alice-gateway/internal/device_provider"
+	sstProvider "sstcloud-alice-gateway/internal/device_provider/sst"
+	"sstcloud-alice-gateway/internal/device_provider/wrap_logger"
+	"sstcloud-alice-gateway/internal/i18n"
+	"sstcloud-alice-gateway/internal/models/alice"
+	storageModels "sstcloud-alice-gateway/internal/models/storage"
+	aliceNotifier "sstcloud-alice-gateway/internal/notifier/alice"
+	"sstcloud-alice-gateway/internal/notifier/alice/alicetest"
+	"sstcloud-alice-gateway/internal/notifier/broadcast"
+	"sstcloud-alice-gateway/internal/notifier/composite"
+	"sstcloud-alice-gateway/internal/services"
+	"sstcloud-alice-gateway/internal/services/checker"
+	"sstcloud-alice-gateway/internal/services/rest"
+	"sstcloud-alice-gateway/internal/storage"
+	sqlStorage "sstcloud-alice-gateway/internal/storage/sql"
+	"sstcloud-alice-gateway/pkg/sst/ssttest"
+)
+
+const (
+	skillID     = "e2e-skill"
+	oauthToken  = "e2e-token"
+	requestID   = "e2e-request"
+	callbackTTL = 5 * time.Second
+)
+
+// gateway шлюз целиком: SQLite, эмулятор SST и фейковый приемник callback'ов Яндекса, без сети
+type gateway struct {
+	t       *testing.T
+	sst     *ssttest.Server
+	yandex  *alicetest.Server
+	storage storage.Storage
+	api     *httptest.Server
+}
+
+func newGateway(t *testing.T) *gateway {
+	t.Helper()
+	g := &gateway{
+		t:      t,
+		sst:    ssttest.NewServer(),
+		yandex: alicetest.NewServer(skillID, oauthToken),
+	}
+	t.Cleanup(g.sst.Close)
+	t.Cleanup(g.yandex.Close)
+
+	ctx := zerolog.New(zerolog.NewTestWriter(t)).Level(zerolog.WarnLevel).WithContext(context.Background())
+	store := sqlStorage.New(sqlStorage.Config{
+		ConnectionString: "sqlite3://" + filepath.Join(t.TempDir(), "gateway.db") + "?_busy_timeout=5000",
+		LogOnlyErrors:    true,
+		AutoMigrate:      true,
+	})
+	if err := store.Connect(ctx); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := store.Disconnect(ctx); err != nil {
+			t.Errorf("disconnect: %v", err)
+		}
+	})
+	g.storage = store
+	return g
+}
+
+// addLink привязывает аккаунт SST к пользователю Алисы
+func (g *gateway) addLink(userID, email, password string) {
+	g.t.Helper()
+	credentials, _ := json.Marshal(sstProvider.Credentials{EMail: email, Password: password})
+	if err := g.storage.AddLink(context.Background(), &storageModels.Link{
+		UserID:      userID,
+		Provider:    sstProvider.ProviderName,
+		Credentials: string(credentials),
+	}); err != nil {
+		g.t.Fatalf("add link: %v", err)
+	}
+}
+
+// start запускает сервисы в том же порядке, что и main; REST отдается через httptest
+func (g *gateway) start() {
+	g.t.Helper()
+	logger := zerolog.New(zerolog.NewTestWriter(g.t)).Level(zerolog.WarnLevel)
+	ctx, cancel := context.WithCancel(logger.WithContext(context.Background()))
+
+	sstProvider.Register(sstProvider.Config{Config: g.sst.Config()})
+	store := g.storage
+
+	broadcaster := broadcast.New()
+	notifier := aliceNotifier.New(aliceNotifier.Config{
+		SkillID:        skillID,
+		Address:        g.yandex.URL,
+		RequestTimeout: time.Second,
+		OAuth2Token:    oauthToken,
+		BatchWindow:    10 * time.Millisecond,
+		RetryMin:       10 * time.Millisecond,
+		RetryMax:       100 * time.Millisecond,
+		Persist:        true,
+	}, store)
+	checkerInstance := checker.New(checker.Config{
+		RequestPeriod:        200 * time.Millisecond,
+		MinRequestPeriod:     50 * time.Millisecond,
+		RequestPeriodBackoff: 2,
+		LinksPeriod:          100 * time.Millisecond,
+	}, store, func(link *storageModels.Link) (device_provider.DeviceProvider, error) {
+		language, _ := i18n.Parse(link.Language)
+		layout, _ := device_provider.ParseLayout(link.Layout, device_provider.LayoutSplit)
+		provider, err := device_provider.New(link.ProviderName(), device_provider.Settings{
+			Credentials: link.ProviderCredentials(),
+			Language:    language,
+			Layout:      layout,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return wrap_logger.New(provider, link.UserID, link.ID, store), nil
+	}, composite.New(broadcaster, notifier))
+	restService, err := rest.New(ctx, rest.Config{}, logger, store, checkerInstance, notifier, broadcaster)
+	if err != nil {
+		g.t.Fatal(err)
+	}
+	g.api = httptest.NewServer(restService.Handler())
+
+	group := &run.Group{}
+	group.Add(func() error {
+		<-ctx.Done()
+		return nil
+	}, func(error) {
+		cancel()
+	})
+	orderRunner := services.OrderRunner{}
+	if err := orderRunner.SetupService(ctx, notifier, "notifier_alice", group); err != nil {
+		g.t.Fatal(err)
+	}
+	if err := orderRunner.SetupService(ctx, checkerInstance, "checker", group); err != nil {
+		g.t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- group.Run()
+	}()
+	g.t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			g.t.Errorf("gateway stopped with error: %v", err)
+		}
+		g.api.Close()
+	})
+}
+
+// discovery запрос списка устройств, как его делает Яндекс
+func (g *gateway) discovery(userID string) alice.Devices {
+	g.t.Helper()
+	return g.call(http.MethodGet, "/v1.0/user/devices", userID, nil)
+}
+
+func (g *gateway) query(userID string, ids ...string) alice.Devices {
+	g.t.Helper()
+	req := alice.QueryRequest{}
+	for _, id := range ids {
+		req.Devices = append(req.Devices, alice.DeviceRequest{ID: id})
+	}
+	return g.call(http.MethodPost, "/v1.0/user/devices/query", userID, req)
+}
+
+func (g *gateway) action(userID string, devices ...alice.DeviceRequest) alice.Devices {
+	g.t.Helper()
+	var req alice.ActionRequest
+	req.Payload.Devices = devices
+	return g.call(http.MethodPost, "/v1.0/user/devices/action", userID, req)
+}
+
+func (g *gateway) call(method, path, userID string, body interface{}) alice.Devices {
+	g.t.Helper()
+	resp := g.do(method, path, userID, body, http.StatusOK)
+	defer resp.Body.Close()
+	var result struct {
+		RequestID string        `json:"request_id"`
+		Payload   alice.Devices `json:"payload"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		g.t.Fatal(err)
+	}
+	if result.RequestID != requestID {
+		g.t.Errorf("%s %s: request id %q is not echoed", method, path, result.RequestID)
+	}
+	return result.Payload
+}
+
+// refresh просит шлюз сразу перечитать дом, минуя кеш провайдера
+func (g *gateway) refresh(userID string, houseID int) {
+	g.t.Helper()
+	g.do(http.MethodPost, "/api/v1/houses/"+strconv.Itoa(houseID)+"/refresh", userID, nil, http.StatusAccepted).Body.Close()
+}
+
+func (g *gateway) do(method, path, userID string, body interface{}, status int) *http.Response {
+	g.t.Helper()
+	var reader io.Reader = http.NoBody
+	if body != nil {
+		blob, err := json.Marshal(body)
+		if err != nil {
+			g.t.Fatal(err)
+		}
+		reader = bytes.NewReader(blob)
+	}
+	req, err := http.NewRequest(method, g.api.URL+path, reader)
+	if err != nil {
+		g.t.Fatal(err)
+	}
+	req.Header.Set("X-User-Id", userID)
+	req.Header.Set("X-Request-Id", requestID)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		g.t.Fatal(err)
+	}
+	if resp.StatusCode != status {
+		resp.Body.Close()
+		g.t.Fatalf("%s %s: unexpected status %s", method, path, resp.Status)
+	}
+	return resp
+}
+
+// waitDevices ждет, пока шлюз опросит SST и отдаст count устройств пользователю
+func (g *gateway) waitDevices(userID string, count int) alice.Devices {
+	g.t.Helper()
+	var result alice.Devices
+	eventually(g.t, func() bool {
+		result = g.discovery(userID)
+		return len(result.Devices) == count
+	})
+	return result
+}
+
+func eventually(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(callbackTTL)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition is not met in time")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
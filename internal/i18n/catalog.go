@@ -0,0 +1,80 @@
+package i18n
+
+import "strings"
+
+type Language string
+
+const (
+	LangRu Language = "ru"
+	LangEn Language = "en"
+	LangKk Language = "kk"
+
+	DefaultLanguage = LangRu
+)
+
+// Messages тексты, которые шлюз генерирует сам: имена датчиков и описания ошибок
+type Messages struct {
+	SensorAir         string
+	SensorFloor       string
+	UnknownAction     string
+	ValueOutOfRange   string
+	InvalidValue      string
+	DeviceUnreachable string
+	DeviceNotFound    string
+	DeviceBusy        string
+	DeviceOffline     string
+}
+
+var catalog = map[Language]*Messages{
+	LangRu: {
+		SensorAir:         "температура воздуха",
+		SensorFloor:       "температура пола",
+		UnknownAction:     "неизвестное действие %s",
+		ValueOutOfRange:   "значение %v вне диапазона %v-%v",
+		InvalidValue:      "некорректное значение: %s",
+		DeviceUnreachable: "устройство недоступно: %s",
+		DeviceNotFound:    "устройство %s не найдено",
+		DeviceBusy:        "устройство выполняет предыдущую команду",
+		DeviceOffline:     "устройство не в сети",
+	},
+	LangEn: {
+		SensorAir:         "air temperature",
+		SensorFloor:       "floor temperature",
+		UnknownAction:     "unknown action %s",
+		ValueOutOfRange:   "value %v not in range %v-%v",
+		InvalidValue:      "invalid value: %s",
+		DeviceUnreachable: "device unreachable: %s",
+		DeviceNotFound:    "device %s not found",
+		DeviceBusy:        "device is executing previous command",
+		DeviceOffline:     "device is offline",
+	},
+	LangKk: {
+		SensorAir:         "ауа температурасы",
+		SensorFloor:       "еден температурасы",
+		UnknownAction:     "белгісіз әрекет %s",
+		ValueOutOfRange:   "%v мәні %v-%v ауқымынан тыс",
+		InvalidValue:      "жарамсыз мән: %s",
+		DeviceUnreachable: "құрылғы қолжетімсіз: %s",
+		DeviceNotFound:    "%s құрылғысы табылмады",
+		DeviceBusy:        "құрылғы алдыңғы команданы орындауда",
+		DeviceOffline:     "құрылғы желіде емес",
+	},
+}
+
+// Parse разбирает код языка, пустая строка соответствует языку по умолчанию
+func Parse(s string) (Language, bool) {
+	if s == "" {
+		return DefaultLanguage, true
+	}
+	lang := Language(strings.ToLower(s))
+	_, exists := catalog[lang]
+	return lang, exists
+}
+
+// For возвращает каталог языка, для неизвестного языка используется язык по умолчанию
+func For(lang Language) *Messages {
+	if messages, exists := catalog[lang]; exists {
+		return messages
+	}
+	return catalog[DefaultLanguage]
+}
@@ -1,122 +1,127 @@
 package mappers
 
 import (
-	"strings"
-
 	"sstcloud-alice-gateway/internal/device_provider"
+	"sstcloud-alice-gateway/internal/i18n"
 	"sstcloud-alice-gateway/internal/models/alice"
-)
-
-const (
-	MinTemp = 12
-	MaxTemp = 45
+	"sstcloud-alice-gateway/internal/profiles"
 )
 
 const (
 	AdditionalSensor      = "sensor"
-	AdditionalSensorAir   = "air"
-	AdditionalSensorFloor = "floor"
+	AdditionalSensorAir   = profiles.SensorAir
+	AdditionalSensorFloor = profiles.SensorFloor
 )
 
+// DeviceToAlice строит устройства Алисы по профилю модели: основное устройство и по одному на каждый датчик,
+// в объединенном представлении основной датчик становится свойством основного устройства.
 func DeviceToAlice(device *device_provider.Device) []alice.Device {
+	messages := i18n.For(device.House.Language)
+	profile := profiles.For(device.Model)
 	result := []alice.Device{{
-		ID:   device.IDStr,
+		ID:   DeviceIDOf(device).String(),
 		Name: device.Name,
+		Room: device.House.Name,
 		DeviceInfo: &alice.DeviceInfo{
 			Model: device.Model,
 		},
 		CustomData: device.AdditionalFields,
-		Type:       alice.DeviceTypeThermostat,
-		Capabilities: []interface{}{
-			alice.CapabilityOnOff{
-				Type:        alice.CapabilityTypeOnOff,
-				Retrievable: true,
-				Parameters: alice.CapabilityOnOffParameters{
-					Split: false,
-				},
-				State: alice.CapabilityOnOffState{
-					Instance: alice.CapabilityOnOffInstanceOn,
-					Value:    device.Enabled,
+		Type:       profile.DeviceType,
+	}}
+	for _, capability := range profile.Capabilities {
+		result[0].Capabilities = append(result[0].Capabilities, capabilityToAlice(device, capability))
+	}
+	for _, sensor := range profile.Sensors {
+		if device.House.Layout == device_provider.LayoutCombined && sensor.Primary {
+			// Яндекс допускает только одно свойство temperature на устройство, поэтому к основному
+			// устройству присоединяется только основной датчик, остальные остаются отдельными.
+			result[0].Properties = append(result[0].Properties, sensorProperty(device, sensor))
+			continue
+		}
+		result = append(result, sensorToAlice(device, sensor, messages))
+	}
+	return result
+}
+
+// SensorToAlice отдельное устройство датчика независимо от представления: по нему отвечают на запросы
+// идентификатора основного датчика, оставшегося у Алисы после переключения в объединенное представление.
+func SensorToAlice(device *device_provider.Device, sensorID string) (alice.Device, bool) {
+	sensor, exists := profiles.For(device.Model).Sensor(sensorID)
+	if !exists {
+		return alice.Device{}, false
+	}
+	return sensorToAlice(device, sensor, i18n.For(device.House.Language)), true
+}
+
+func sensorToAlice(device *device_provider.Device, sensor profiles.Sensor, messages *i18n.Messages) alice.Device {
+	return alice.Device{
+		ID:   DeviceIDOf(device).WithSensor(sensor.ID).String(),
+		Name: device.Name + " " + sensor.Name(messages),
+		Room: device.House.Name,
+		DeviceInfo: &alice.DeviceInfo{
+			Model: device.Model,
+		},
+		CustomData: mapMux(device.AdditionalFields, map[string]string{
+			AdditionalSensor: sensor.ID,
+		}),
+		Type:       alice.DeviceTypeSensor,
+		Properties: []alice.Property{sensorProperty(device, sensor)},
+	}
+}
+
+func capabilityToAlice(device *device_provider.Device, capability profiles.Capability) interface{} {
+	switch capability {
+	case profiles.CapabilityTemperature:
+		return alice.CapabilityRange{
+			Type:        alice.CapabilityTypeRange,
+			Retrievable: true,
+			Parameters: alice.CapabilityRangeParametersTemperature{
+				Instance:     alice.CapabilityRangeInstanceTemperature,
+				Unit:         alice.PropertyParameterUnitCelsius,
+				RandomAccess: true,
+				Range: alice.CapabilityRangeParametersRange{
+					Max:       float32(device.Limits.Max.Float()),
+					Min:       float32(device.Limits.Min.Float()),
+					Precision: float32(device.Limits.Precision.Float()),
 				},
 			},
-			alice.CapabilityRange{
-				Type:        alice.CapabilityTypeRange,
-				Retrievable: true,
-				Parameters: alice.CapabilityRangeParametersTemperature{
-					Instance:     alice.CapabilityRangeInstanceTemperature,
-					Unit:         alice.PropertyParameterUnitCelsius,
-					RandomAccess: true,
-					Range: alice.CapabilityRangeParametersRange{
-						Max:       MaxTemp,
-						Min:       MinTemp,
-						Precision: 1,
-					},
-				},
-				State: alice.CapabilityRangeStateTemperature{
-					Instance: alice.CapabilityRangeInstanceTemperature,
-					Value:    float32(device.Tempometer.SetDegreesFloor),
-				},
+			State: alice.CapabilityRangeStateTemperature{
+				Instance: alice.CapabilityRangeInstanceTemperature,
+				Value:    float32(device.Tempometer.SetDegreesFloor.Float()),
 			},
-		},
-	},
-		{
-			ID:   createDeviceID(device.IDStr, AdditionalSensorAir),
-			Name: device.Name + " температура воздуха",
-			DeviceInfo: &alice.DeviceInfo{
-				Model: device.Model,
+		}
+	default:
+		return alice.CapabilityOnOff{
+			Type:        alice.CapabilityTypeOnOff,
+			Retrievable: true,
+			Parameters: alice.CapabilityOnOffParameters{
+				Split: false,
 			},
-			CustomData: mapMux(device.AdditionalFields, map[string]string{
-				AdditionalSensor: AdditionalSensorAir,
-			}),
-			Type: alice.DeviceTypeSensor,
-			Properties: []alice.Property{
-				{
-					Type:        alice.PropertyTypeFloat,
-					Retrievable: true,
-					Reportable:  true,
-					Parameters: alice.PropertyParameter{
-						Instance: alice.PropertyParameterInstanceTemperature,
-						Unit:     alice.PropertyParameterUnitCelsius,
-					},
-					State: alice.PayloadStateDevicePropertiesState{
-						Instance: alice.PropertyParameterInstanceTemperature,
-						Value:    device.Tempometer.DegreesAir,
-					},
-					LastUpdated:    device.UpdatedAt,
-					StateChangedAt: device.Tempometer.ChangedAtDegreesAir,
-				},
+			State: alice.CapabilityOnOffState{
+				Instance: alice.CapabilityOnOffInstanceOn,
+				Value:    device.Enabled,
 			},
+		}
+	}
+}
+
+func sensorProperty(device *device_provider.Device, sensor profiles.Sensor) alice.Property {
+	value, changedAt := sensor.Value(device)
+	return alice.Property{
+		Type:        alice.PropertyTypeFloat,
+		Retrievable: true,
+		Reportable:  true,
+		Parameters: alice.PropertyParameter{
+			Instance: alice.PropertyParameterInstanceTemperature,
+			Unit:     alice.PropertyParameterUnitCelsius,
 		},
-		{
-			ID:   createDeviceID(device.IDStr, AdditionalSensorFloor),
-			Name: device.Name + " температура пола",
-			DeviceInfo: &alice.DeviceInfo{
-				Model: device.Model,
-			},
-			CustomData: mapMux(device.AdditionalFields, map[string]string{
-				AdditionalSensor: AdditionalSensorFloor,
-			}),
-			Type: alice.DeviceTypeSensor,
-			Properties: []alice.Property{
-				{
-					Type:        alice.PropertyTypeFloat,
-					Retrievable: true,
-					Reportable:  true,
-					Parameters: alice.PropertyParameter{
-						Instance: alice.PropertyParameterInstanceTemperature,
-						Unit:     alice.PropertyParameterUnitCelsius,
-					},
-					State: alice.PayloadStateDevicePropertiesState{
-						Instance: alice.PropertyParameterInstanceTemperature,
-						Value:    device.Tempometer.DegreesFloor,
-					},
-					LastUpdated:    device.UpdatedAt,
-					StateChangedAt: device.Tempometer.ChangedAtDegreesFloor,
-				},
-			},
+		State: alice.PayloadStateDevicePropertiesState{
+			Instance: alice.PropertyParameterInstanceTemperature,
+			Value:    value.Float(),
 		},
+		LastUpdated:    device.UpdatedAt,
+		StateChangedAt: changedAt,
 	}
-	return result
 }
 
 func mapMux(m1, m2 map[string]string) map[string]string {
@@ -129,7 +134,3 @@ func mapMux(m1, m2 map[string]string) map[string]string {
 	}
 	return result
 }
-
-func createDeviceID(str1, str2 string) string {
-	return strings.Join([]string{str1, str2}, "_")
-}
@@ -0,0 +1,210 @@
+package mappers
+
+import (
+	"testing"
+
+	"sstcloud-alice-gateway/internal/device_provider"
+	"sstcloud-alice-gateway/internal/i18n"
+	"sstcloud-alice-gateway/internal/models/alice"
+	"sstcloud-alice-gateway/internal/profiles"
+	"sstcloud-alice-gateway/pkg/sst"
+)
+
+var models = []sst.DeviceType{sst.MCS300, sst.MCS350}
+
+func testDevice(model sst.DeviceType, layout device_provider.Layout) *device_provider.Device {
+	profile := profiles.For(model.String())
+	return &device_provider.Device{
+		House: &device_provider.House{
+			ID:       1,
+			Name:     "Дача",
+			Language: i18n.LangEn,
+			Layout:   layout,
+		},
+		ID:        2,
+		Name:      "Kitchen",
+		Model:     model.String(),
+		Enabled:   true,
+		Connected: true,
+		Tempometer: device_provider.Tempometer{
+			SetDegreesFloor: device_provider.Degrees(25),
+			DegreesFloor:    device_provider.Degrees(23),
+			DegreesAir:      device_provider.Degrees(21),
+		},
+		Limits: profile.Limits,
+	}
+}
+
+func TestDeviceToAliceSplit(t *testing.T) {
+	for _, model := range models {
+		t.Run(model.String(), func(t *testing.T) {
+			devices := DeviceToAlice(testDevice(model, device_provider.LayoutSplit))
+			if len(devices) != 3 {
+				t.Fatalf("expected thermostat and two sensors, got %+v", devices)
+			}
+			thermostat := devices[0]
+			if thermostat.ID != "1_2" || thermostat.Type != alice.DeviceTypeThermostat || thermostat.Room != "Дача" ||
+				len(thermostat.Capabilities) != 2 || len(thermostat.Properties) != 0 {
+				t.Fatalf("unexpected thermostat %+v", thermostat)
+			}
+			want := map[string]struct {
+				name  string
+				value float64
+			}{
+				"1_2_air":   {name: "Kitchen air temperature", value: 21},
+				"1_2_floor": {name: "Kitchen floor temperature", value: 23},
+			}
+			for _, sensor := range devices[1:] {
+				expected, exists := want[sensor.ID]
+				if !exists || sensor.Type != alice.DeviceTypeSensor || sensor.Name != expected.name || len(sensor.Properties) != 1 {
+					t.Fatalf("unexpected sensor %+v", sensor)
+				}
+				if value := sensor.Properties[0].State.Value; value != expected.value {
+					t.Fatalf("%s: value %v, want %v", sensor.ID, value, expected.value)
+				}
+				if _, err := ParseDeviceID(sensor.ID); err != nil {
+					t.Fatalf("%s: %v", sensor.ID, err)
+				}
+			}
+		})
+	}
+}
+
+func TestDeviceToAliceCombined(t *testing.T) {
+	for _, model := range models {
+		t.Run(model.String(), func(t *testing.T) {
+			devices := DeviceToAlice(testDevice(model, device_provider.LayoutCombined))
+			if len(devices) != 2 {
+				t.Fatalf("expected thermostat and air sensor, got %+v", devices)
+			}
+			thermostat := devices[0]
+			if thermostat.ID != "1_2" || thermostat.Type != alice.DeviceTypeThermostat || len(thermostat.Capabilities) != 2 {
+				t.Fatalf("unexpected thermostat %+v", thermostat)
+			}
+			// к термостату присоединяется только основной датчик пола: Яндекс допускает одно свойство temperature
+			if props := thermostat.Properties; len(props) != 1 || props[0].State.Value != 23.0 {
+				t.Fatalf("expected single floor property, got %+v", props)
+			}
+			air := devices[1]
+			if air.ID != "1_2_air" || air.Type != alice.DeviceTypeSensor || len(air.Properties) != 1 || air.Properties[0].State.Value != 21.0 {
+				t.Fatalf("unexpected air sensor %+v", air)
+			}
+		})
+	}
+}
+
+func TestSensorToAlice(t *testing.T) {
+	device := testDevice(sst.MCS350, device_provider.LayoutCombined)
+	sensor, exists := SensorToAlice(device, AdditionalSensorFloor)
+	if !exists || sensor.ID != "1_2_floor" || len(sensor.Properties) != 1 || sensor.Properties[0].State.Value != 23.0 {
+		t.Fatalf("unexpected sensor %+v", sensor)
+	}
+	if _, exists := SensorToAlice(device, ""); exists {
+		t.Fatal("thermostat id must not resolve to sensor")
+	}
+	if _, exists := SensorToAlice(device, "humidity"); exists {
+		t.Fatal("unknown sensor must not resolve")
+	}
+}
+
+func TestDeviceToAliceState(t *testing.T) {
+	tests := []struct {
+		name    string
+		layout  device_provider.Layout
+		changes device_provider.Changes
+		want    map[string][]float64
+	}{
+		{
+			name:    "split air",
+			layout:  device_provider.LayoutSplit,
+			changes: device_provider.ChangedDegreesAir,
+			want:    map[string][]float64{"1_2_air": {21}},
+		},
+		{
+			name:    "split floor",
+			layout:  device_provider.LayoutSplit,
+			changes: device_provider.ChangedDegreesFloor,
+			want:    map[string][]float64{"1_2_floor": {23}},
+		},
+		{
+			name:    "combined air",
+			layout:  device_provider.LayoutCombined,
+			changes: device_provider.ChangedDegreesAir,
+			want:    map[string][]float64{"1_2_air": {21}},
+		},
+		{
+			name:    "combined floor",
+			layout:  device_provider.LayoutCombined,
+			changes: device_provider.ChangedDegreesFloor,
+			want:    map[string][]float64{"1_2": {23}},
+		},
+		{
+			name:    "combined both",
+			layout:  device_provider.LayoutCombined,
+			changes: device_provider.ChangedDegreesAir | device_provider.ChangedDegreesFloor,
+			want:    map[string][]float64{"1_2": {23}, "1_2_air": {21}},
+		},
+		{
+			name:    "setpoint only",
+			layout:  device_provider.LayoutCombined,
+			changes: device_provider.ChangedSetDegreesFloor,
+			want:    map[string][]float64{"1_2": nil},
+		},
+	}
+	for _, model := range models {
+		for _, tt := range tests {
+			t.Run(model.String()+" "+tt.name, func(t *testing.T) {
+				states := DeviceToAliceState(testDevice(model, tt.layout), tt.changes)
+				if len(states) != len(tt.want) {
+					t.Fatalf("got %+v, want %v", states, tt.want)
+				}
+				for _, state := range states {
+					want, exists := tt.want[state.ID]
+					if !exists || len(state.Properties) != len(want) {
+						t.Fatalf("unexpected state %+v", state)
+					}
+					for i, prop := range state.Properties {
+						if prop.State.Value != want[i] {
+							t.Fatalf("%s: property %d = %v, want %v", state.ID, i, prop.State.Value, want[i])
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestParseDeviceID(t *testing.T) {
+	tests := []struct {
+		id      string
+		want    DeviceID
+		wantErr bool
+	}{
+		{id: "1_2", want: DeviceID{HouseID: 1, DeviceID: 2}},
+		{id: "1_2_air", want: DeviceID{HouseID: 1, DeviceID: 2, Sensor: AdditionalSensorAir}},
+		{id: "1_2_floor", want: DeviceID{HouseID: 1, DeviceID: 2, Sensor: AdditionalSensorFloor}},
+		{id: "1_2_humidity", wantErr: true},
+		{id: "1_2_", wantErr: true},
+		{id: "1_2_air_floor", wantErr: true},
+		{id: "1", wantErr: true},
+		{id: "a_2", wantErr: true},
+		{id: "1_-2", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			got, err := ParseDeviceID(tt.id)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("got %+v, %v, want %+v", got, err, tt.want)
+			}
+			if got.String() != tt.id {
+				t.Fatalf("round trip %q != %q", got.String(), tt.id)
+			}
+		})
+	}
+}
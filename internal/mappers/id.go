@@ -0,0 +1,88 @@
+package mappers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"sstcloud-alice-gateway/internal/device_provider"
+	"sstcloud-alice-gateway/internal/profiles"
+)
+
+const deviceIDSeparator = "_"
+
+var ErrInvalidDeviceID = errors.New("invalid device id")
+
+// DeviceID идентификатор устройства в Алисе: "<house>_<device>" для термостата и "<house>_<device>_<sensor>" для датчика
+type DeviceID struct {
+	HouseID  int
+	DeviceID int
+	Sensor   string
+}
+
+func DeviceIDOf(device *device_provider.Device) DeviceID {
+	return DeviceID{
+		HouseID:  device.House.ID,
+		DeviceID: device.ID,
+	}
+}
+
+func (id DeviceID) WithSensor(sensor string) DeviceID {
+	id.Sensor = sensor
+	return id
+}
+
+// Base идентификатор термостата, к которому относится датчик
+func (id DeviceID) Base() DeviceID {
+	id.Sensor = ""
+	return id
+}
+
+func (id DeviceID) String() string {
+	parts := []string{strconv.Itoa(id.HouseID), strconv.Itoa(id.DeviceID)}
+	if id.Sensor != "" {
+		parts = append(parts, id.Sensor)
+	}
+	return strings.Join(parts, deviceIDSeparator)
+}
+
+func ParseDeviceID(s string) (DeviceID, error) {
+	parts := strings.Split(s, deviceIDSeparator)
+	if len(parts) != 2 && len(parts) != 3 {
+		return DeviceID{}, ErrInvalidDeviceID
+	}
+	houseID, err := strconv.Atoi(parts[0])
+	if err != nil || houseID < 0 {
+		return DeviceID{}, ErrInvalidDeviceID
+	}
+	deviceID, err := strconv.Atoi(parts[1])
+	if err != nil || deviceID < 0 {
+		return DeviceID{}, ErrInvalidDeviceID
+	}
+	id := DeviceID{
+		HouseID:  houseID,
+		DeviceID: deviceID,
+	}
+	if len(parts) == 3 {
+		if !isSensorID(parts[2]) {
+			return DeviceID{}, ErrInvalidDeviceID
+		}
+		id.Sensor = parts[2]
+	}
+	return id, nil
+}
+
+// FindDevice ищет термостат, к которому относится идентификатор
+func FindDevice(devices []*device_provider.Device, id DeviceID) *device_provider.Device {
+	for _, device := range devices {
+		if device.House.ID == id.HouseID && device.ID == id.DeviceID {
+			return device
+		}
+	}
+	return nil
+}
+
+// isSensorID суффикс одного из датчиков профилей
+func isSensorID(s string) bool {
+	return profiles.HasSensor(s)
+}
@@ -0,0 +1,83 @@
+package mappers
+
+import (
+	"strings"
+
+	"sstcloud-alice-gateway/internal/models/alice"
+	"sstcloud-alice-gateway/internal/models/storage"
+)
+
+// ApplySettings применяет пользовательские настройки к устройствам discovery: скрытые устройства убираются,
+// заданные имя, комната и описание заменяют сгенерированные.
+// Датчики термостата следуют его настройкам: скрываются вместе с ним, переезжают в его комнату и получают
+// его новое имя с прежним суффиксом датчика, если для датчика не задано собственное.
+func ApplySettings(devices []alice.Device, settings []*storage.DeviceSetting) []alice.Device {
+	settingsMap := settingsByID(settings)
+	names := make(map[string]string, len(devices))
+	for _, device := range devices {
+		names[device.ID] = device.Name
+	}
+	result := make([]alice.Device, 0, len(devices))
+	for _, device := range devices {
+		setting, parent := settingsMap[device.ID], parentSetting(settingsMap, device.ID)
+		if isHidden(setting, parent) {
+			continue
+		}
+		switch {
+		case setting != nil && setting.Name != "":
+			device.Name = setting.Name
+		case parent != nil && parent.Name != "":
+			// "<имя термостата> <датчик>" -> "<новое имя> <датчик>"
+			if base := names[parentID(device.ID)]; base != "" && strings.HasPrefix(device.Name, base) {
+				device.Name = parent.Name + strings.TrimPrefix(device.Name, base)
+			}
+		}
+		switch {
+		case setting != nil && setting.Room != "":
+			device.Room = setting.Room
+		case parent != nil && parent.Room != "":
+			device.Room = parent.Room
+		}
+		if setting != nil && setting.Description != "" {
+			device.Description = setting.Description
+		}
+		result = append(result, device)
+	}
+	return result
+}
+
+// IsHidden скрыто ли устройство Алисы настройками пользователя, такие устройства не управляются и не опрашиваются
+func IsHidden(settings []*storage.DeviceSetting, deviceID string) bool {
+	settingsMap := settingsByID(settings)
+	return isHidden(settingsMap[deviceID], parentSetting(settingsMap, deviceID))
+}
+
+func isHidden(setting, parent *storage.DeviceSetting) bool {
+	return (setting != nil && setting.Hidden) || (parent != nil && parent.Hidden)
+}
+
+func settingsByID(settings []*storage.DeviceSetting) map[string]*storage.DeviceSetting {
+	result := make(map[string]*storage.DeviceSetting, len(settings))
+	for _, setting := range settings {
+		result[setting.DeviceID] = setting
+	}
+	return result
+}
+
+// parentSetting настройка термостата, к которому относится датчик
+func parentSetting(settingsMap map[string]*storage.DeviceSetting, deviceID string) *storage.DeviceSetting {
+	id := parentID(deviceID)
+	if id == "" {
+		return nil
+	}
+	return settingsMap[id]
+}
+
+// parentID идентификатор термостата для датчика, для остальных устройств пустая строка
+func parentID(deviceID string) string {
+	id, err := ParseDeviceID(deviceID)
+	if err != nil || id.Sensor == "" {
+		return ""
+	}
+	return id.Base().String()
+}
@@ -0,0 +1,92 @@
+package mappers
+
+import (
+	"testing"
+
+	"sstcloud-alice-gateway/internal/models/alice"
+	"sstcloud-alice-gateway/internal/models/storage"
+)
+
+func TestApplySettings(t *testing.T) {
+	devices := []alice.Device{
+		{ID: "1_2", Name: "Теплый пол", Room: "Дом"},
+		{ID: "1_2_air", Name: "Теплый пол воздух", Room: "Дом"},
+		{ID: "1_2_floor", Name: "Теплый пол пол", Room: "Дом"},
+		{ID: "1_3", Name: "Ванная", Room: "Дом"},
+		{ID: "1_3_air", Name: "Ванная воздух", Room: "Дом"},
+	}
+	tests := []struct {
+		name     string
+		settings []*storage.DeviceSetting
+		want     []alice.Device
+	}{
+		{
+			name: "no settings",
+			want: devices,
+		},
+		{
+			name: "thermostat rename applies to its sensors",
+			settings: []*storage.DeviceSetting{
+				{DeviceID: "1_2", Name: "Кухня", Room: "Кухня", Description: "пол"},
+			},
+			want: []alice.Device{
+				{ID: "1_2", Name: "Кухня", Room: "Кухня", Description: "пол"},
+				{ID: "1_2_air", Name: "Кухня воздух", Room: "Кухня"},
+				{ID: "1_2_floor", Name: "Кухня пол", Room: "Кухня"},
+				devices[3],
+				devices[4],
+			},
+		},
+		{
+			name: "sensor setting wins over thermostat",
+			settings: []*storage.DeviceSetting{
+				{DeviceID: "1_2", Name: "Кухня", Room: "Кухня"},
+				{DeviceID: "1_2_air", Name: "Воздух на кухне", Room: "Коридор"},
+			},
+			want: []alice.Device{
+				{ID: "1_2", Name: "Кухня", Room: "Кухня"},
+				{ID: "1_2_air", Name: "Воздух на кухне", Room: "Коридор"},
+				{ID: "1_2_floor", Name: "Кухня пол", Room: "Кухня"},
+				devices[3],
+				devices[4],
+			},
+		},
+		{
+			name: "hidden thermostat hides its sensors",
+			settings: []*storage.DeviceSetting{
+				{DeviceID: "1_2", Hidden: true},
+				{DeviceID: "1_3_air", Hidden: true},
+			},
+			want: []alice.Device{devices[3]},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ApplySettings(devices, tt.settings)
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %d devices, got %+v", len(tt.want), got)
+			}
+			for i := range tt.want {
+				if got[i].ID != tt.want[i].ID || got[i].Name != tt.want[i].Name || got[i].Room != tt.want[i].Room || got[i].Description != tt.want[i].Description {
+					t.Errorf("device %d: got %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIsHidden(t *testing.T) {
+	settings := []*storage.DeviceSetting{{DeviceID: "1_2", Hidden: true}, {DeviceID: "1_3_air", Hidden: true}}
+	for id, want := range map[string]bool{
+		"1_2":       true,
+		"1_2_air":   true,
+		"1_3":       false,
+		"1_3_air":   true,
+		"1_3_floor": false,
+		"garbage":   false,
+	} {
+		if got := IsHidden(settings, id); got != want {
+			t.Errorf("%s: got %v, want %v", id, got, want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package mappers
+
+import (
+	"sstcloud-alice-gateway/internal/device_provider"
+	"sstcloud-alice-gateway/internal/models/alice"
+	"sstcloud-alice-gateway/internal/profiles"
+)
+
+// DeviceToAliceState возвращает для callback'а только изменившиеся умения и свойства устройства.
+func DeviceToAliceState(device *device_provider.Device, changes device_provider.Changes) []alice.PayloadStateDevice {
+	if changes.Has(device_provider.ChangedConnected) {
+		changes = device_provider.ChangedAll
+	}
+	profile := profiles.For(device.Model)
+	var result []alice.PayloadStateDevice
+	for _, obj := range DeviceToAlice(device) {
+		state := alice.PayloadStateDevice{
+			ID: obj.ID,
+		}
+		for _, capability := range obj.Capabilities {
+			switch c := capability.(type) {
+			case alice.CapabilityOnOff:
+				if changes.Has(device_provider.ChangedEnabled) {
+					state.Capabilities = append(state.Capabilities, alice.PayloadStateDeviceCapabilities{Type: c.Type, State: c.State})
+				}
+			case alice.CapabilityRange:
+				if changes.Has(device_provider.ChangedSetDegreesFloor) {
+					state.Capabilities = append(state.Capabilities, alice.PayloadStateDeviceCapabilities{Type: c.Type, State: c.State})
+				}
+			}
+		}
+		sensor, exists := profile.Sensor(obj.CustomData[AdditionalSensor])
+		if !exists {
+			// свойство основного устройства в объединенном режиме
+			sensor, exists = profile.Primary()
+		}
+		if exists && changes.Has(sensor.Changes) {
+			for _, prop := range obj.Properties {
+				state.Properties = append(state.Properties, alice.PayloadStateDeviceProperties{
+					Type:  prop.Type,
+					State: prop.State,
+				})
+			}
+		}
+		if len(state.Capabilities) == 0 && len(state.Properties) == 0 {
+			continue
+		}
+		result = append(result, state)
+	}
+	return result
+}
@@ -0,0 +1,91 @@
+package alice
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+var ErrUnsupportedCapability = errors.New("unsupported capability")
+
+// ValidationError некорректное значение умения, отвечаем INVALID_VALUE
+type ValidationError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Reason)
+}
+
+type CapabilityRequest struct {
+	Type  CapabilityType         `json:"type"`
+	State CapabilityRequestState `json:"state"`
+}
+
+// CapabilityRequestState значение хранится как есть и разбирается в Decode по типу умения
+type CapabilityRequestState struct {
+	Instance string          `json:"instance"`
+	Value    json.RawMessage `json:"value"`
+	Relative bool            `json:"relative,omitempty"`
+}
+
+// CapabilityAction разобранное действие: OnOffAction или RangeAction
+type CapabilityAction interface {
+	capabilityAction()
+}
+
+type OnOffAction struct {
+	Instance CapabilityOnOffInstance
+	Value    bool
+}
+
+type RangeAction struct {
+	Instance CapabilityRangeInstance
+	Value    float64
+	Relative bool
+}
+
+func (OnOffAction) capabilityAction() {}
+func (RangeAction) capabilityAction() {}
+
+// Decode проверяет и разбирает действие. Неизвестный тип умения возвращает ErrUnsupportedCapability,
+// некорректное значение *ValidationError.
+func (c CapabilityRequest) Decode() (CapabilityAction, error) {
+	switch c.Type {
+	case CapabilityTypeOnOff:
+		if CapabilityOnOffInstance(c.State.Instance) != CapabilityOnOffInstanceOn {
+			return nil, &ValidationError{Field: "state.instance", Reason: fmt.Sprintf("unexpected instance %q", c.State.Instance)}
+		}
+		if c.State.Relative {
+			return nil, &ValidationError{Field: "state.relative", Reason: "relative is not supported for on_off"}
+		}
+		var value bool
+		if err := decodeValue(c.State.Value, &value); err != nil {
+			return nil, &ValidationError{Field: "state.value", Reason: "boolean expected"}
+		}
+		return OnOffAction{Instance: CapabilityOnOffInstance(c.State.Instance), Value: value}, nil
+	case CapabilityTypeRange:
+		if c.State.Instance == "" {
+			return nil, &ValidationError{Field: "state.instance", Reason: "instance is required"}
+		}
+		var value float64
+		if err := decodeValue(c.State.Value, &value); err != nil {
+			return nil, &ValidationError{Field: "state.value", Reason: "number expected"}
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return nil, &ValidationError{Field: "state.value", Reason: "finite number expected"}
+		}
+		return RangeAction{Instance: CapabilityRangeInstance(c.State.Instance), Value: value, Relative: c.State.Relative}, nil
+	}
+	return nil, ErrUnsupportedCapability
+}
+
+func decodeValue(raw json.RawMessage, v interface{}) error {
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return errors.New("value is required")
+	}
+	return json.Unmarshal(raw, v)
+}
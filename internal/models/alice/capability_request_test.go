@@ -0,0 +1,101 @@
+package alice
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCapabilityRequestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    CapabilityAction
+		wantErr error
+	}{
+		{
+			name:    "on_off on",
+			payload: `{"type":"devices.capabilities.on_off","state":{"instance":"on","value":true}}`,
+			want:    OnOffAction{Instance: CapabilityOnOffInstanceOn, Value: true},
+		},
+		{
+			name:    "on_off off",
+			payload: `{"type":"devices.capabilities.on_off","state":{"instance":"on","value":false}}`,
+			want:    OnOffAction{Instance: CapabilityOnOffInstanceOn, Value: false},
+		},
+		{
+			name:    "on_off string value",
+			payload: `{"type":"devices.capabilities.on_off","state":{"instance":"on","value":"true"}}`,
+			wantErr: &ValidationError{},
+		},
+		{
+			name:    "on_off missing value",
+			payload: `{"type":"devices.capabilities.on_off","state":{"instance":"on"}}`,
+			wantErr: &ValidationError{},
+		},
+		{
+			name:    "on_off null value",
+			payload: `{"type":"devices.capabilities.on_off","state":{"instance":"on","value":null}}`,
+			wantErr: &ValidationError{},
+		},
+		{
+			name:    "on_off unknown instance",
+			payload: `{"type":"devices.capabilities.on_off","state":{"instance":"mute","value":true}}`,
+			wantErr: &ValidationError{},
+		},
+		{
+			name:    "range absolute",
+			payload: `{"type":"devices.capabilities.range","state":{"instance":"temperature","value":25}}`,
+			want:    RangeAction{Instance: CapabilityRangeInstanceTemperature, Value: 25},
+		},
+		{
+			name:    "range relative",
+			payload: `{"type":"devices.capabilities.range","state":{"instance":"temperature","value":-2,"relative":true}}`,
+			want:    RangeAction{Instance: CapabilityRangeInstanceTemperature, Value: -2, Relative: true},
+		},
+		{
+			name:    "range bool value",
+			payload: `{"type":"devices.capabilities.range","state":{"instance":"temperature","value":true}}`,
+			wantErr: &ValidationError{},
+		},
+		{
+			name:    "range missing instance",
+			payload: `{"type":"devices.capabilities.range","state":{"value":20}}`,
+			wantErr: &ValidationError{},
+		},
+		{
+			name:    "unsupported capability",
+			payload: `{"type":"devices.capabilities.mode","state":{"instance":"program","value":"auto"}}`,
+			wantErr: ErrUnsupportedCapability,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req CapabilityRequest
+			if err := json.Unmarshal([]byte(tt.payload), &req); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			got, err := req.Decode()
+			switch want := tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			case *ValidationError:
+				if !errors.As(err, &want) {
+					t.Fatalf("expected validation error, got %v", err)
+				}
+				return
+			default:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
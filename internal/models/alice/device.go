@@ -13,14 +13,18 @@ const (
 
 type Device struct {
 	ID           string            `json:"id"`
-	Name         string            `json:"name"`
+	Name         string            `json:"name,omitempty"`
 	Description  string            `json:"description,omitempty"`
 	Room         string            `json:"room,omitempty"`
-	Type         DeviceType        `json:"type"`
+	Type         DeviceType        `json:"type,omitempty"`
 	CustomData   map[string]string `json:"custom_data,omitempty"`
 	Capabilities []interface{}     `json:"capabilities,omitempty"`
 	Properties   []Property        `json:"properties,omitempty"`
 	DeviceInfo   *DeviceInfo       `json:"device_info,omitempty"`
+	// ErrorCode и ErrorMessage заполняются только в ответе на query, в discovery кодов ошибок нет
+	ErrorCode    ErrorCode     `json:"error_code,omitempty"`
+	ErrorMessage string        `json:"error_message,omitempty"`
+	ActionResult *ActionResult `json:"action_result,omitempty"`
 }
 
 type DeviceInfo struct {
@@ -47,6 +51,8 @@ const (
 type ErrorCode string
 
 const (
+	ErrorCodeDeviceNotFound    ErrorCode = "DEVICE_NOT_FOUND"
+	ErrorCodeDeviceBusy        ErrorCode = "DEVICE_BUSY"
 	ErrorCodeDeviceUnreachable ErrorCode = "DEVICE_UNREACHABLE"
 	ErrorCodeInvalidAction     ErrorCode = "INVALID_ACTION"
 	ErrorCodeInvalidValue      ErrorCode = "INVALID_VALUE"
@@ -171,14 +177,7 @@ type Property struct {
 }
 
 type DeviceRequest struct {
-	ID           string            `json:"id"`
-	CustomData   map[string]string `json:"custom_data"`
-	Capabilities []struct {
-		Type  CapabilityType `json:"type"`
-		State struct {
-			Instance string      `json:"instance"`
-			Value    interface{} `json:"value"`
-			Relative bool        `json:"relative"`
-		}
-	} `json:"capabilities"`
+	ID           string              `json:"id"`
+	CustomData   map[string]string   `json:"custom_data"`
+	Capabilities []CapabilityRequest `json:"capabilities"`
 }
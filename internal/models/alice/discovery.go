@@ -0,0 +1,10 @@
+package alice
+
+type Discovery struct {
+	TS      int64            `json:"ts"`
+	Payload PayloadDiscovery `json:"payload"`
+}
+
+type PayloadDiscovery struct {
+	UserID string `json:"user_id"`
+}
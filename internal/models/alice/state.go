@@ -11,9 +11,14 @@ type PayloadState struct {
 }
 
 type PayloadStateDevice struct {
-	ID           string                         `json:"id"`
-	Properties   []PayloadStateDeviceProperties `json:"properties"`
-	Capabilities []interface{}                  `json:"capabilities"`
+	ID           string                           `json:"id"`
+	Properties   []PayloadStateDeviceProperties   `json:"properties"`
+	Capabilities []PayloadStateDeviceCapabilities `json:"capabilities"`
+}
+
+type PayloadStateDeviceCapabilities struct {
+	Type  CapabilityType `json:"type"`
+	State interface{}    `json:"state"`
 }
 
 type PayloadStateDeviceProperties struct {
@@ -2,19 +2,40 @@
 package storage
 
 import (
+	"encoding/json"
 	"time"
+
+	"github.com/google/uuid"
 )
 
+// setID идентификаторы генерируются в Go: схема не зависит от uuid-ossp и одинакова для postgres и sqlite
+func setID(id *string) {
+	if *id == "" {
+		*id = uuid.NewString()
+	}
+}
+
 //reform:links
 type Link struct {
 	ID          string    `reform:"id,pk"`
 	UserID      string    `reform:"user_id"`
 	SSTEmail    string    `reform:"sst_email"`
 	SSTPassword string    `reform:"sst_password"`
+	Provider    string    `reform:"provider"`
+	Credentials string    `reform:"credentials"`
+	Language    string    `reform:"language"`
+	Layout      string    `reform:"device_layout"`
 	CreatedAt   time.Time `reform:"created_at"`
 	UpdatedAt   time.Time `reform:"updated_at"`
 }
 
+func (s *Link) BeforeInsert() error {
+	setID(&s.ID)
+	s.CreatedAt = time.Now()
+	s.UpdatedAt = s.CreatedAt
+	return nil
+}
+
 func (s *Link) BeforeUpdate() error {
 	s.UpdatedAt = time.Now()
 	return nil
@@ -23,7 +44,48 @@ func (s *Link) BeforeUpdate() error {
 func (s *Link) Equal(o *Link) bool {
 	return s.ID == o.ID &&
 		s.SSTEmail == o.SSTEmail &&
-		s.SSTPassword == o.SSTPassword
+		s.SSTPassword == o.SSTPassword &&
+		s.Provider == o.Provider &&
+		s.Credentials == o.Credentials &&
+		s.Language == o.Language &&
+		s.Layout == o.Layout
+}
+
+const legacyProvider = "sst"
+
+// ProviderName провайдер привязки, старые привязки без провайдера относятся к SST
+func (s *Link) ProviderName() string {
+	if s.Provider == "" {
+		return legacyProvider
+	}
+	return s.Provider
+}
+
+// ProviderCredentials учетные данные провайдера, для старых привязок SST берутся из sst_email/sst_password
+func (s *Link) ProviderCredentials() json.RawMessage {
+	if s.Credentials != "" && s.Credentials != "{}" {
+		return json.RawMessage(s.Credentials)
+	}
+	if s.ProviderName() != legacyProvider {
+		return json.RawMessage("{}")
+	}
+	data, _ := json.Marshal(map[string]string{
+		"email":    s.SSTEmail,
+		"password": s.SSTPassword,
+	})
+	return data
+}
+
+// Email учетная запись провайдера для отображения пользователю, пустая, если провайдер ее не использует
+func (s *Link) Email() string {
+	if s.SSTEmail != "" {
+		return s.SSTEmail
+	}
+	var credentials struct {
+		Email string `json:"email"`
+	}
+	_ = json.Unmarshal(s.ProviderCredentials(), &credentials)
+	return credentials.Email
 }
 
 type LogLevel string
@@ -41,3 +103,159 @@ type Log struct {
 	Level   LogLevel  `reform:"level"`
 	Message string    `reform:"message"`
 }
+
+func (s *Log) BeforeInsert() error {
+	setID(&s.ID)
+	return nil
+}
+
+//reform:notifications
+type Notification struct {
+	ID            string    `reform:"id,pk"`
+	UserID        string    `reform:"user_id"`
+	Payload       string    `reform:"payload"`
+	Discovery     bool      `reform:"discovery"`
+	Attempts      int       `reform:"attempts"`
+	NextAttemptAt time.Time `reform:"next_attempt_at"`
+	LastError     string    `reform:"last_error"`
+	CreatedAt     time.Time `reform:"created_at"`
+	UpdatedAt     time.Time `reform:"updated_at"`
+}
+
+func (s *Notification) BeforeInsert() error {
+	setID(&s.ID)
+	s.CreatedAt = time.Now()
+	s.UpdatedAt = s.CreatedAt
+	return nil
+}
+
+func (s *Notification) BeforeUpdate() error {
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+//reform:webhooks
+type Webhook struct {
+	ID        string    `reform:"id,pk"`
+	UserID    string    `reform:"user_id"`
+	URL       string    `reform:"url"`
+	Secret    string    `reform:"secret"`
+	CreatedAt time.Time `reform:"created_at"`
+	UpdatedAt time.Time `reform:"updated_at"`
+}
+
+func (s *Webhook) BeforeInsert() error {
+	setID(&s.ID)
+	s.CreatedAt = time.Now()
+	s.UpdatedAt = s.CreatedAt
+	return nil
+}
+
+func (s *Webhook) BeforeUpdate() error {
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+//reform:webhook_deliveries
+type WebhookDelivery struct {
+	ID         string    `reform:"id,pk"`
+	WebhookID  string    `reform:"webhook_id"`
+	Event      string    `reform:"event"`
+	Payload    string    `reform:"payload"`
+	Attempt    int       `reform:"attempt"`
+	StatusCode int       `reform:"status_code"`
+	Error      string    `reform:"error"`
+	Time       time.Time `reform:"time"`
+}
+
+func (s *WebhookDelivery) BeforeInsert() error {
+	setID(&s.ID)
+	return nil
+}
+
+//reform:measurements
+type Measurement struct {
+	ID              string    `reform:"id,pk"`
+	UserID          string    `reform:"user_id"`
+	DeviceID        string    `reform:"device_id"`
+	Time            time.Time `reform:"time"`
+	DegreesAir      float64   `reform:"degrees_air"`
+	DegreesFloor    float64   `reform:"degrees_floor"`
+	SetDegreesFloor float64   `reform:"set_degrees_floor"`
+	Enabled         bool      `reform:"enabled"`
+}
+
+func (s *Measurement) BeforeInsert() error {
+	setID(&s.ID)
+	return nil
+}
+
+type CommandOrigin string
+
+const (
+	CommandOriginAlice   CommandOrigin = "alice"
+	CommandOriginAdmin   CommandOrigin = "admin"
+	CommandOriginMQTT    CommandOrigin = "mqtt"
+	CommandOriginUnknown CommandOrigin = "unknown"
+)
+
+type CommandName string
+
+const (
+	CommandPower       CommandName = "power"
+	CommandTemperature CommandName = "temperature"
+)
+
+type CommandStatus string
+
+const (
+	CommandStatusSuccess CommandStatus = "success"
+	CommandStatusError   CommandStatus = "error"
+)
+
+//reform:commands
+type Command struct {
+	ID         string        `reform:"id,pk"`
+	UserID     string        `reform:"user_id"`
+	LinkID     *string       `reform:"link_id"`
+	DeviceID   string        `reform:"device_id"`
+	DeviceName string        `reform:"device_name"`
+	Command    CommandName   `reform:"command"`
+	OldValue   string        `reform:"old_value"`
+	NewValue   string        `reform:"new_value"`
+	Origin     CommandOrigin `reform:"origin"`
+	RequestID  string        `reform:"request_id"`
+	Status     CommandStatus `reform:"status"`
+	Error      string        `reform:"error"`
+	Time       time.Time     `reform:"time"`
+}
+
+func (s *Command) BeforeInsert() error {
+	setID(&s.ID)
+	return nil
+}
+
+//reform:device_settings
+type DeviceSetting struct {
+	ID          string    `reform:"id,pk"`
+	UserID      string    `reform:"user_id"`
+	DeviceID    string    `reform:"device_id"`
+	Name        string    `reform:"name"`
+	Room        string    `reform:"room"`
+	Description string    `reform:"description"`
+	Hidden      bool      `reform:"hidden"`
+	CreatedAt   time.Time `reform:"created_at"`
+	UpdatedAt   time.Time `reform:"updated_at"`
+}
+
+func (s *DeviceSetting) BeforeInsert() error {
+	setID(&s.ID)
+	s.CreatedAt = time.Now()
+	s.UpdatedAt = s.CreatedAt
+	return nil
+}
+
+func (s *DeviceSetting) BeforeUpdate() error {
+	s.UpdatedAt = time.Now()
+	return nil
+}
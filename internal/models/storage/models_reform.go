@@ -32,6 +32,10 @@ func (v *linkTableType) Columns() []string {
 		"user_id",
 		"sst_email",
 		"sst_password",
+		"provider",
+		"credentials",
+		"language",
+		"device_layout",
 		"created_at",
 		"updated_at",
 	}
@@ -62,6 +66,10 @@ var LinkTable = &linkTableType{
 			{Name: "UserID", Type: "string", Column: "user_id"},
 			{Name: "SSTEmail", Type: "string", Column: "sst_email"},
 			{Name: "SSTPassword", Type: "string", Column: "sst_password"},
+			{Name: "Provider", Type: "string", Column: "provider"},
+			{Name: "Credentials", Type: "string", Column: "credentials"},
+			{Name: "Language", Type: "string", Column: "language"},
+			{Name: "Layout", Type: "string", Column: "device_layout"},
 			{Name: "CreatedAt", Type: "time.Time", Column: "created_at"},
 			{Name: "UpdatedAt", Type: "time.Time", Column: "updated_at"},
 		},
@@ -72,13 +80,17 @@ var LinkTable = &linkTableType{
 
 // String returns a string representation of this struct or record.
 func (s Link) String() string {
-	res := make([]string, 6)
+	res := make([]string, 10)
 	res[0] = "ID: " + reform.Inspect(s.ID, true)
 	res[1] = "UserID: " + reform.Inspect(s.UserID, true)
 	res[2] = "SSTEmail: " + reform.Inspect(s.SSTEmail, true)
 	res[3] = "SSTPassword: " + reform.Inspect(s.SSTPassword, true)
-	res[4] = "CreatedAt: " + reform.Inspect(s.CreatedAt, true)
-	res[5] = "UpdatedAt: " + reform.Inspect(s.UpdatedAt, true)
+	res[4] = "Provider: " + reform.Inspect(s.Provider, true)
+	res[5] = "Credentials: " + reform.Inspect(s.Credentials, true)
+	res[6] = "Language: " + reform.Inspect(s.Language, true)
+	res[7] = "Layout: " + reform.Inspect(s.Layout, true)
+	res[8] = "CreatedAt: " + reform.Inspect(s.CreatedAt, true)
+	res[9] = "UpdatedAt: " + reform.Inspect(s.UpdatedAt, true)
 	return strings.Join(res, ", ")
 }
 
@@ -90,6 +102,10 @@ func (s *Link) Values() []interface{} {
 		s.UserID,
 		s.SSTEmail,
 		s.SSTPassword,
+		s.Provider,
+		s.Credentials,
+		s.Language,
+		s.Layout,
 		s.CreatedAt,
 		s.UpdatedAt,
 	}
@@ -103,6 +119,10 @@ func (s *Link) Pointers() []interface{} {
 		&s.UserID,
 		&s.SSTEmail,
 		&s.SSTPassword,
+		&s.Provider,
+		&s.Credentials,
+		&s.Language,
+		&s.Layout,
 		&s.CreatedAt,
 		&s.UpdatedAt,
 	}
@@ -287,7 +307,944 @@ var (
 	_ fmt.Stringer  = (*Log)(nil)
 )
 
+type notificationTableType struct {
+	s parse.StructInfo
+	z []interface{}
+}
+
+// Schema returns a schema name in SQL database ("").
+func (v *notificationTableType) Schema() string {
+	return v.s.SQLSchema
+}
+
+// Name returns a view or table name in SQL database ("notifications").
+func (v *notificationTableType) Name() string {
+	return v.s.SQLName
+}
+
+// Columns returns a new slice of column names for that view or table in SQL database.
+func (v *notificationTableType) Columns() []string {
+	return []string{
+		"id",
+		"user_id",
+		"payload",
+		"discovery",
+		"attempts",
+		"next_attempt_at",
+		"last_error",
+		"created_at",
+		"updated_at",
+	}
+}
+
+// NewStruct makes a new struct for that view or table.
+func (v *notificationTableType) NewStruct() reform.Struct {
+	return new(Notification)
+}
+
+// NewRecord makes a new record for that table.
+func (v *notificationTableType) NewRecord() reform.Record {
+	return new(Notification)
+}
+
+// PKColumnIndex returns an index of primary key column for that table in SQL database.
+func (v *notificationTableType) PKColumnIndex() uint {
+	return uint(v.s.PKFieldIndex)
+}
+
+// NotificationTable represents notifications view or table in SQL database.
+var NotificationTable = &notificationTableType{
+	s: parse.StructInfo{
+		Type:    "Notification",
+		SQLName: "notifications",
+		Fields: []parse.FieldInfo{
+			{Name: "ID", Type: "string", Column: "id"},
+			{Name: "UserID", Type: "string", Column: "user_id"},
+			{Name: "Payload", Type: "string", Column: "payload"},
+			{Name: "Discovery", Type: "bool", Column: "discovery"},
+			{Name: "Attempts", Type: "int", Column: "attempts"},
+			{Name: "NextAttemptAt", Type: "time.Time", Column: "next_attempt_at"},
+			{Name: "LastError", Type: "string", Column: "last_error"},
+			{Name: "CreatedAt", Type: "time.Time", Column: "created_at"},
+			{Name: "UpdatedAt", Type: "time.Time", Column: "updated_at"},
+		},
+		PKFieldIndex: 0,
+	},
+	z: new(Notification).Values(),
+}
+
+// String returns a string representation of this struct or record.
+func (s Notification) String() string {
+	res := make([]string, 9)
+	res[0] = "ID: " + reform.Inspect(s.ID, true)
+	res[1] = "UserID: " + reform.Inspect(s.UserID, true)
+	res[2] = "Payload: " + reform.Inspect(s.Payload, true)
+	res[3] = "Discovery: " + reform.Inspect(s.Discovery, true)
+	res[4] = "Attempts: " + reform.Inspect(s.Attempts, true)
+	res[5] = "NextAttemptAt: " + reform.Inspect(s.NextAttemptAt, true)
+	res[6] = "LastError: " + reform.Inspect(s.LastError, true)
+	res[7] = "CreatedAt: " + reform.Inspect(s.CreatedAt, true)
+	res[8] = "UpdatedAt: " + reform.Inspect(s.UpdatedAt, true)
+	return strings.Join(res, ", ")
+}
+
+// Values returns a slice of struct or record field values.
+// Returned interface{} values are never untyped nils.
+func (s *Notification) Values() []interface{} {
+	return []interface{}{
+		s.ID,
+		s.UserID,
+		s.Payload,
+		s.Discovery,
+		s.Attempts,
+		s.NextAttemptAt,
+		s.LastError,
+		s.CreatedAt,
+		s.UpdatedAt,
+	}
+}
+
+// Pointers returns a slice of pointers to struct or record fields.
+// Returned interface{} values are never untyped nils.
+func (s *Notification) Pointers() []interface{} {
+	return []interface{}{
+		&s.ID,
+		&s.UserID,
+		&s.Payload,
+		&s.Discovery,
+		&s.Attempts,
+		&s.NextAttemptAt,
+		&s.LastError,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	}
+}
+
+// View returns View object for that struct.
+func (s *Notification) View() reform.View {
+	return NotificationTable
+}
+
+// Table returns Table object for that record.
+func (s *Notification) Table() reform.Table {
+	return NotificationTable
+}
+
+// PKValue returns a value of primary key for that record.
+// Returned interface{} value is never untyped nil.
+func (s *Notification) PKValue() interface{} {
+	return s.ID
+}
+
+// PKPointer returns a pointer to primary key field for that record.
+// Returned interface{} value is never untyped nil.
+func (s *Notification) PKPointer() interface{} {
+	return &s.ID
+}
+
+// HasPK returns true if record has non-zero primary key set, false otherwise.
+func (s *Notification) HasPK() bool {
+	return s.ID != NotificationTable.z[NotificationTable.s.PKFieldIndex]
+}
+
+// SetPK sets record primary key, if possible.
+//
+// Deprecated: prefer direct field assignment where possible: s.ID = pk.
+func (s *Notification) SetPK(pk interface{}) {
+	reform.SetPK(s, pk)
+}
+
+// check interfaces
+var (
+	_ reform.View   = NotificationTable
+	_ reform.Struct = (*Notification)(nil)
+	_ reform.Table  = NotificationTable
+	_ reform.Record = (*Notification)(nil)
+	_ fmt.Stringer  = (*Notification)(nil)
+)
+
+type webhookTableType struct {
+	s parse.StructInfo
+	z []interface{}
+}
+
+// Schema returns a schema name in SQL database ("").
+func (v *webhookTableType) Schema() string {
+	return v.s.SQLSchema
+}
+
+// Name returns a view or table name in SQL database ("webhooks").
+func (v *webhookTableType) Name() string {
+	return v.s.SQLName
+}
+
+// Columns returns a new slice of column names for that view or table in SQL database.
+func (v *webhookTableType) Columns() []string {
+	return []string{
+		"id",
+		"user_id",
+		"url",
+		"secret",
+		"created_at",
+		"updated_at",
+	}
+}
+
+// NewStruct makes a new struct for that view or table.
+func (v *webhookTableType) NewStruct() reform.Struct {
+	return new(Webhook)
+}
+
+// NewRecord makes a new record for that table.
+func (v *webhookTableType) NewRecord() reform.Record {
+	return new(Webhook)
+}
+
+// PKColumnIndex returns an index of primary key column for that table in SQL database.
+func (v *webhookTableType) PKColumnIndex() uint {
+	return uint(v.s.PKFieldIndex)
+}
+
+// WebhookTable represents webhooks view or table in SQL database.
+var WebhookTable = &webhookTableType{
+	s: parse.StructInfo{
+		Type:    "Webhook",
+		SQLName: "webhooks",
+		Fields: []parse.FieldInfo{
+			{Name: "ID", Type: "string", Column: "id"},
+			{Name: "UserID", Type: "string", Column: "user_id"},
+			{Name: "URL", Type: "string", Column: "url"},
+			{Name: "Secret", Type: "string", Column: "secret"},
+			{Name: "CreatedAt", Type: "time.Time", Column: "created_at"},
+			{Name: "UpdatedAt", Type: "time.Time", Column: "updated_at"},
+		},
+		PKFieldIndex: 0,
+	},
+	z: new(Webhook).Values(),
+}
+
+// String returns a string representation of this struct or record.
+func (s Webhook) String() string {
+	res := make([]string, 6)
+	res[0] = "ID: " + reform.Inspect(s.ID, true)
+	res[1] = "UserID: " + reform.Inspect(s.UserID, true)
+	res[2] = "URL: " + reform.Inspect(s.URL, true)
+	res[3] = "Secret: " + reform.Inspect(s.Secret, true)
+	res[4] = "CreatedAt: " + reform.Inspect(s.CreatedAt, true)
+	res[5] = "UpdatedAt: " + reform.Inspect(s.UpdatedAt, true)
+	return strings.Join(res, ", ")
+}
+
+// Values returns a slice of struct or record field values.
+// Returned interface{} values are never untyped nils.
+func (s *Webhook) Values() []interface{} {
+	return []interface{}{
+		s.ID,
+		s.UserID,
+		s.URL,
+		s.Secret,
+		s.CreatedAt,
+		s.UpdatedAt,
+	}
+}
+
+// Pointers returns a slice of pointers to struct or record fields.
+// Returned interface{} values are never untyped nils.
+func (s *Webhook) Pointers() []interface{} {
+	return []interface{}{
+		&s.ID,
+		&s.UserID,
+		&s.URL,
+		&s.Secret,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	}
+}
+
+// View returns View object for that struct.
+func (s *Webhook) View() reform.View {
+	return WebhookTable
+}
+
+// Table returns Table object for that record.
+func (s *Webhook) Table() reform.Table {
+	return WebhookTable
+}
+
+// PKValue returns a value of primary key for that record.
+// Returned interface{} value is never untyped nil.
+func (s *Webhook) PKValue() interface{} {
+	return s.ID
+}
+
+// PKPointer returns a pointer to primary key field for that record.
+// Returned interface{} value is never untyped nil.
+func (s *Webhook) PKPointer() interface{} {
+	return &s.ID
+}
+
+// HasPK returns true if record has non-zero primary key set, false otherwise.
+func (s *Webhook) HasPK() bool {
+	return s.ID != WebhookTable.z[WebhookTable.s.PKFieldIndex]
+}
+
+// SetPK sets record primary key, if possible.
+//
+// Deprecated: prefer direct field assignment where possible: s.ID = pk.
+func (s *Webhook) SetPK(pk interface{}) {
+	reform.SetPK(s, pk)
+}
+
+// check interfaces
+var (
+	_ reform.View   = WebhookTable
+	_ reform.Struct = (*Webhook)(nil)
+	_ reform.Table  = WebhookTable
+	_ reform.Record = (*Webhook)(nil)
+	_ fmt.Stringer  = (*Webhook)(nil)
+)
+
+type webhookDeliveryTableType struct {
+	s parse.StructInfo
+	z []interface{}
+}
+
+// Schema returns a schema name in SQL database ("").
+func (v *webhookDeliveryTableType) Schema() string {
+	return v.s.SQLSchema
+}
+
+// Name returns a view or table name in SQL database ("webhook_deliveries").
+func (v *webhookDeliveryTableType) Name() string {
+	return v.s.SQLName
+}
+
+// Columns returns a new slice of column names for that view or table in SQL database.
+func (v *webhookDeliveryTableType) Columns() []string {
+	return []string{
+		"id",
+		"webhook_id",
+		"event",
+		"payload",
+		"attempt",
+		"status_code",
+		"error",
+		"time",
+	}
+}
+
+// NewStruct makes a new struct for that view or table.
+func (v *webhookDeliveryTableType) NewStruct() reform.Struct {
+	return new(WebhookDelivery)
+}
+
+// NewRecord makes a new record for that table.
+func (v *webhookDeliveryTableType) NewRecord() reform.Record {
+	return new(WebhookDelivery)
+}
+
+// PKColumnIndex returns an index of primary key column for that table in SQL database.
+func (v *webhookDeliveryTableType) PKColumnIndex() uint {
+	return uint(v.s.PKFieldIndex)
+}
+
+// WebhookDeliveryTable represents webhook_deliveries view or table in SQL database.
+var WebhookDeliveryTable = &webhookDeliveryTableType{
+	s: parse.StructInfo{
+		Type:    "WebhookDelivery",
+		SQLName: "webhook_deliveries",
+		Fields: []parse.FieldInfo{
+			{Name: "ID", Type: "string", Column: "id"},
+			{Name: "WebhookID", Type: "string", Column: "webhook_id"},
+			{Name: "Event", Type: "string", Column: "event"},
+			{Name: "Payload", Type: "string", Column: "payload"},
+			{Name: "Attempt", Type: "int", Column: "attempt"},
+			{Name: "StatusCode", Type: "int", Column: "status_code"},
+			{Name: "Error", Type: "string", Column: "error"},
+			{Name: "Time", Type: "time.Time", Column: "time"},
+		},
+		PKFieldIndex: 0,
+	},
+	z: new(WebhookDelivery).Values(),
+}
+
+// String returns a string representation of this struct or record.
+func (s WebhookDelivery) String() string {
+	res := make([]string, 8)
+	res[0] = "ID: " + reform.Inspect(s.ID, true)
+	res[1] = "WebhookID: " + reform.Inspect(s.WebhookID, true)
+	res[2] = "Event: " + reform.Inspect(s.Event, true)
+	res[3] = "Payload: " + reform.Inspect(s.Payload, true)
+	res[4] = "Attempt: " + reform.Inspect(s.Attempt, true)
+	res[5] = "StatusCode: " + reform.Inspect(s.StatusCode, true)
+	res[6] = "Error: " + reform.Inspect(s.Error, true)
+	res[7] = "Time: " + reform.Inspect(s.Time, true)
+	return strings.Join(res, ", ")
+}
+
+// Values returns a slice of struct or record field values.
+// Returned interface{} values are never untyped nils.
+func (s *WebhookDelivery) Values() []interface{} {
+	return []interface{}{
+		s.ID,
+		s.WebhookID,
+		s.Event,
+		s.Payload,
+		s.Attempt,
+		s.StatusCode,
+		s.Error,
+		s.Time,
+	}
+}
+
+// Pointers returns a slice of pointers to struct or record fields.
+// Returned interface{} values are never untyped nils.
+func (s *WebhookDelivery) Pointers() []interface{} {
+	return []interface{}{
+		&s.ID,
+		&s.WebhookID,
+		&s.Event,
+		&s.Payload,
+		&s.Attempt,
+		&s.StatusCode,
+		&s.Error,
+		&s.Time,
+	}
+}
+
+// View returns View object for that struct.
+func (s *WebhookDelivery) View() reform.View {
+	return WebhookDeliveryTable
+}
+
+// Table returns Table object for that record.
+func (s *WebhookDelivery) Table() reform.Table {
+	return WebhookDeliveryTable
+}
+
+// PKValue returns a value of primary key for that record.
+// Returned interface{} value is never untyped nil.
+func (s *WebhookDelivery) PKValue() interface{} {
+	return s.ID
+}
+
+// PKPointer returns a pointer to primary key field for that record.
+// Returned interface{} value is never untyped nil.
+func (s *WebhookDelivery) PKPointer() interface{} {
+	return &s.ID
+}
+
+// HasPK returns true if record has non-zero primary key set, false otherwise.
+func (s *WebhookDelivery) HasPK() bool {
+	return s.ID != WebhookDeliveryTable.z[WebhookDeliveryTable.s.PKFieldIndex]
+}
+
+// SetPK sets record primary key, if possible.
+//
+// Deprecated: prefer direct field assignment where possible: s.ID = pk.
+func (s *WebhookDelivery) SetPK(pk interface{}) {
+	reform.SetPK(s, pk)
+}
+
+// check interfaces
+var (
+	_ reform.View   = WebhookDeliveryTable
+	_ reform.Struct = (*WebhookDelivery)(nil)
+	_ reform.Table  = WebhookDeliveryTable
+	_ reform.Record = (*WebhookDelivery)(nil)
+	_ fmt.Stringer  = (*WebhookDelivery)(nil)
+)
+
+type measurementTableType struct {
+	s parse.StructInfo
+	z []interface{}
+}
+
+// Schema returns a schema name in SQL database ("").
+func (v *measurementTableType) Schema() string {
+	return v.s.SQLSchema
+}
+
+// Name returns a view or table name in SQL database ("measurements").
+func (v *measurementTableType) Name() string {
+	return v.s.SQLName
+}
+
+// Columns returns a new slice of column names for that view or table in SQL database.
+func (v *measurementTableType) Columns() []string {
+	return []string{
+		"id",
+		"user_id",
+		"device_id",
+		"time",
+		"degrees_air",
+		"degrees_floor",
+		"set_degrees_floor",
+		"enabled",
+	}
+}
+
+// NewStruct makes a new struct for that view or table.
+func (v *measurementTableType) NewStruct() reform.Struct {
+	return new(Measurement)
+}
+
+// NewRecord makes a new record for that table.
+func (v *measurementTableType) NewRecord() reform.Record {
+	return new(Measurement)
+}
+
+// PKColumnIndex returns an index of primary key column for that table in SQL database.
+func (v *measurementTableType) PKColumnIndex() uint {
+	return uint(v.s.PKFieldIndex)
+}
+
+// MeasurementTable represents measurements view or table in SQL database.
+var MeasurementTable = &measurementTableType{
+	s: parse.StructInfo{
+		Type:    "Measurement",
+		SQLName: "measurements",
+		Fields: []parse.FieldInfo{
+			{Name: "ID", Type: "string", Column: "id"},
+			{Name: "UserID", Type: "string", Column: "user_id"},
+			{Name: "DeviceID", Type: "string", Column: "device_id"},
+			{Name: "Time", Type: "time.Time", Column: "time"},
+			{Name: "DegreesAir", Type: "float64", Column: "degrees_air"},
+			{Name: "DegreesFloor", Type: "float64", Column: "degrees_floor"},
+			{Name: "SetDegreesFloor", Type: "float64", Column: "set_degrees_floor"},
+			{Name: "Enabled", Type: "bool", Column: "enabled"},
+		},
+		PKFieldIndex: 0,
+	},
+	z: new(Measurement).Values(),
+}
+
+// String returns a string representation of this struct or record.
+func (s Measurement) String() string {
+	res := make([]string, 8)
+	res[0] = "ID: " + reform.Inspect(s.ID, true)
+	res[1] = "UserID: " + reform.Inspect(s.UserID, true)
+	res[2] = "DeviceID: " + reform.Inspect(s.DeviceID, true)
+	res[3] = "Time: " + reform.Inspect(s.Time, true)
+	res[4] = "DegreesAir: " + reform.Inspect(s.DegreesAir, true)
+	res[5] = "DegreesFloor: " + reform.Inspect(s.DegreesFloor, true)
+	res[6] = "SetDegreesFloor: " + reform.Inspect(s.SetDegreesFloor, true)
+	res[7] = "Enabled: " + reform.Inspect(s.Enabled, true)
+	return strings.Join(res, ", ")
+}
+
+// Values returns a slice of struct or record field values.
+// Returned interface{} values are never untyped nils.
+func (s *Measurement) Values() []interface{} {
+	return []interface{}{
+		s.ID,
+		s.UserID,
+		s.DeviceID,
+		s.Time,
+		s.DegreesAir,
+		s.DegreesFloor,
+		s.SetDegreesFloor,
+		s.Enabled,
+	}
+}
+
+// Pointers returns a slice of pointers to struct or record fields.
+// Returned interface{} values are never untyped nils.
+func (s *Measurement) Pointers() []interface{} {
+	return []interface{}{
+		&s.ID,
+		&s.UserID,
+		&s.DeviceID,
+		&s.Time,
+		&s.DegreesAir,
+		&s.DegreesFloor,
+		&s.SetDegreesFloor,
+		&s.Enabled,
+	}
+}
+
+// View returns View object for that struct.
+func (s *Measurement) View() reform.View {
+	return MeasurementTable
+}
+
+// Table returns Table object for that record.
+func (s *Measurement) Table() reform.Table {
+	return MeasurementTable
+}
+
+// PKValue returns a value of primary key for that record.
+// Returned interface{} value is never untyped nil.
+func (s *Measurement) PKValue() interface{} {
+	return s.ID
+}
+
+// PKPointer returns a pointer to primary key field for that record.
+// Returned interface{} value is never untyped nil.
+func (s *Measurement) PKPointer() interface{} {
+	return &s.ID
+}
+
+// HasPK returns true if record has non-zero primary key set, false otherwise.
+func (s *Measurement) HasPK() bool {
+	return s.ID != MeasurementTable.z[MeasurementTable.s.PKFieldIndex]
+}
+
+// SetPK sets record primary key, if possible.
+//
+// Deprecated: prefer direct field assignment where possible: s.ID = pk.
+func (s *Measurement) SetPK(pk interface{}) {
+	reform.SetPK(s, pk)
+}
+
+// check interfaces
+var (
+	_ reform.View   = MeasurementTable
+	_ reform.Struct = (*Measurement)(nil)
+	_ reform.Table  = MeasurementTable
+	_ reform.Record = (*Measurement)(nil)
+	_ fmt.Stringer  = (*Measurement)(nil)
+)
+
+type commandTableType struct {
+	s parse.StructInfo
+	z []interface{}
+}
+
+// Schema returns a schema name in SQL database ("").
+func (v *commandTableType) Schema() string {
+	return v.s.SQLSchema
+}
+
+// Name returns a view or table name in SQL database ("commands").
+func (v *commandTableType) Name() string {
+	return v.s.SQLName
+}
+
+// Columns returns a new slice of column names for that view or table in SQL database.
+func (v *commandTableType) Columns() []string {
+	return []string{
+		"id",
+		"user_id",
+		"link_id",
+		"device_id",
+		"device_name",
+		"command",
+		"old_value",
+		"new_value",
+		"origin",
+		"request_id",
+		"status",
+		"error",
+		"time",
+	}
+}
+
+// NewStruct makes a new struct for that view or table.
+func (v *commandTableType) NewStruct() reform.Struct {
+	return new(Command)
+}
+
+// NewRecord makes a new record for that table.
+func (v *commandTableType) NewRecord() reform.Record {
+	return new(Command)
+}
+
+// PKColumnIndex returns an index of primary key column for that table in SQL database.
+func (v *commandTableType) PKColumnIndex() uint {
+	return uint(v.s.PKFieldIndex)
+}
+
+// CommandTable represents commands view or table in SQL database.
+var CommandTable = &commandTableType{
+	s: parse.StructInfo{
+		Type:    "Command",
+		SQLName: "commands",
+		Fields: []parse.FieldInfo{
+			{Name: "ID", Type: "string", Column: "id"},
+			{Name: "UserID", Type: "string", Column: "user_id"},
+			{Name: "LinkID", Type: "*string", Column: "link_id"},
+			{Name: "DeviceID", Type: "string", Column: "device_id"},
+			{Name: "DeviceName", Type: "string", Column: "device_name"},
+			{Name: "Command", Type: "CommandName", Column: "command"},
+			{Name: "OldValue", Type: "string", Column: "old_value"},
+			{Name: "NewValue", Type: "string", Column: "new_value"},
+			{Name: "Origin", Type: "CommandOrigin", Column: "origin"},
+			{Name: "RequestID", Type: "string", Column: "request_id"},
+			{Name: "Status", Type: "CommandStatus", Column: "status"},
+			{Name: "Error", Type: "string", Column: "error"},
+			{Name: "Time", Type: "time.Time", Column: "time"},
+		},
+		PKFieldIndex: 0,
+	},
+	z: new(Command).Values(),
+}
+
+// String returns a string representation of this struct or record.
+func (s Command) String() string {
+	res := make([]string, 13)
+	res[0] = "ID: " + reform.Inspect(s.ID, true)
+	res[1] = "UserID: " + reform.Inspect(s.UserID, true)
+	res[2] = "LinkID: " + reform.Inspect(s.LinkID, true)
+	res[3] = "DeviceID: " + reform.Inspect(s.DeviceID, true)
+	res[4] = "DeviceName: " + reform.Inspect(s.DeviceName, true)
+	res[5] = "Command: " + reform.Inspect(s.Command, true)
+	res[6] = "OldValue: " + reform.Inspect(s.OldValue, true)
+	res[7] = "NewValue: " + reform.Inspect(s.NewValue, true)
+	res[8] = "Origin: " + reform.Inspect(s.Origin, true)
+	res[9] = "RequestID: " + reform.Inspect(s.RequestID, true)
+	res[10] = "Status: " + reform.Inspect(s.Status, true)
+	res[11] = "Error: " + reform.Inspect(s.Error, true)
+	res[12] = "Time: " + reform.Inspect(s.Time, true)
+	return strings.Join(res, ", ")
+}
+
+// Values returns a slice of struct or record field values.
+// Returned interface{} values are never untyped nils.
+func (s *Command) Values() []interface{} {
+	return []interface{}{
+		s.ID,
+		s.UserID,
+		s.LinkID,
+		s.DeviceID,
+		s.DeviceName,
+		s.Command,
+		s.OldValue,
+		s.NewValue,
+		s.Origin,
+		s.RequestID,
+		s.Status,
+		s.Error,
+		s.Time,
+	}
+}
+
+// Pointers returns a slice of pointers to struct or record fields.
+// Returned interface{} values are never untyped nils.
+func (s *Command) Pointers() []interface{} {
+	return []interface{}{
+		&s.ID,
+		&s.UserID,
+		&s.LinkID,
+		&s.DeviceID,
+		&s.DeviceName,
+		&s.Command,
+		&s.OldValue,
+		&s.NewValue,
+		&s.Origin,
+		&s.RequestID,
+		&s.Status,
+		&s.Error,
+		&s.Time,
+	}
+}
+
+// View returns View object for that struct.
+func (s *Command) View() reform.View {
+	return CommandTable
+}
+
+// Table returns Table object for that record.
+func (s *Command) Table() reform.Table {
+	return CommandTable
+}
+
+// PKValue returns a value of primary key for that record.
+// Returned interface{} value is never untyped nil.
+func (s *Command) PKValue() interface{} {
+	return s.ID
+}
+
+// PKPointer returns a pointer to primary key field for that record.
+// Returned interface{} value is never untyped nil.
+func (s *Command) PKPointer() interface{} {
+	return &s.ID
+}
+
+// HasPK returns true if record has non-zero primary key set, false otherwise.
+func (s *Command) HasPK() bool {
+	return s.ID != CommandTable.z[CommandTable.s.PKFieldIndex]
+}
+
+// SetPK sets record primary key, if possible.
+//
+// Deprecated: prefer direct field assignment where possible: s.ID = pk.
+func (s *Command) SetPK(pk interface{}) {
+	reform.SetPK(s, pk)
+}
+
+// check interfaces
+var (
+	_ reform.View   = CommandTable
+	_ reform.Struct = (*Command)(nil)
+	_ reform.Table  = CommandTable
+	_ reform.Record = (*Command)(nil)
+	_ fmt.Stringer  = (*Command)(nil)
+)
+
+type deviceSettingTableType struct {
+	s parse.StructInfo
+	z []interface{}
+}
+
+// Schema returns a schema name in SQL database ("").
+func (v *deviceSettingTableType) Schema() string {
+	return v.s.SQLSchema
+}
+
+// Name returns a view or table name in SQL database ("device_settings").
+func (v *deviceSettingTableType) Name() string {
+	return v.s.SQLName
+}
+
+// Columns returns a new slice of column names for that view or table in SQL database.
+func (v *deviceSettingTableType) Columns() []string {
+	return []string{
+		"id",
+		"user_id",
+		"device_id",
+		"name",
+		"room",
+		"description",
+		"hidden",
+		"created_at",
+		"updated_at",
+	}
+}
+
+// NewStruct makes a new struct for that view or table.
+func (v *deviceSettingTableType) NewStruct() reform.Struct {
+	return new(DeviceSetting)
+}
+
+// NewRecord makes a new record for that table.
+func (v *deviceSettingTableType) NewRecord() reform.Record {
+	return new(DeviceSetting)
+}
+
+// PKColumnIndex returns an index of primary key column for that table in SQL database.
+func (v *deviceSettingTableType) PKColumnIndex() uint {
+	return uint(v.s.PKFieldIndex)
+}
+
+// DeviceSettingTable represents device_settings view or table in SQL database.
+var DeviceSettingTable = &deviceSettingTableType{
+	s: parse.StructInfo{
+		Type:    "DeviceSetting",
+		SQLName: "device_settings",
+		Fields: []parse.FieldInfo{
+			{Name: "ID", Type: "string", Column: "id"},
+			{Name: "UserID", Type: "string", Column: "user_id"},
+			{Name: "DeviceID", Type: "string", Column: "device_id"},
+			{Name: "Name", Type: "string", Column: "name"},
+			{Name: "Room", Type: "string", Column: "room"},
+			{Name: "Description", Type: "string", Column: "description"},
+			{Name: "Hidden", Type: "bool", Column: "hidden"},
+			{Name: "CreatedAt", Type: "time.Time", Column: "created_at"},
+			{Name: "UpdatedAt", Type: "time.Time", Column: "updated_at"},
+		},
+		PKFieldIndex: 0,
+	},
+	z: new(DeviceSetting).Values(),
+}
+
+// String returns a string representation of this struct or record.
+func (s DeviceSetting) String() string {
+	res := make([]string, 9)
+	res[0] = "ID: " + reform.Inspect(s.ID, true)
+	res[1] = "UserID: " + reform.Inspect(s.UserID, true)
+	res[2] = "DeviceID: " + reform.Inspect(s.DeviceID, true)
+	res[3] = "Name: " + reform.Inspect(s.Name, true)
+	res[4] = "Room: " + reform.Inspect(s.Room, true)
+	res[5] = "Description: " + reform.Inspect(s.Description, true)
+	res[6] = "Hidden: " + reform.Inspect(s.Hidden, true)
+	res[7] = "CreatedAt: " + reform.Inspect(s.CreatedAt, true)
+	res[8] = "UpdatedAt: " + reform.Inspect(s.UpdatedAt, true)
+	return strings.Join(res, ", ")
+}
+
+// Values returns a slice of struct or record field values.
+// Returned interface{} values are never untyped nils.
+func (s *DeviceSetting) Values() []interface{} {
+	return []interface{}{
+		s.ID,
+		s.UserID,
+		s.DeviceID,
+		s.Name,
+		s.Room,
+		s.Description,
+		s.Hidden,
+		s.CreatedAt,
+		s.UpdatedAt,
+	}
+}
+
+// Pointers returns a slice of pointers to struct or record fields.
+// Returned interface{} values are never untyped nils.
+func (s *DeviceSetting) Pointers() []interface{} {
+	return []interface{}{
+		&s.ID,
+		&s.UserID,
+		&s.DeviceID,
+		&s.Name,
+		&s.Room,
+		&s.Description,
+		&s.Hidden,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	}
+}
+
+// View returns View object for that struct.
+func (s *DeviceSetting) View() reform.View {
+	return DeviceSettingTable
+}
+
+// Table returns Table object for that record.
+func (s *DeviceSetting) Table() reform.Table {
+	return DeviceSettingTable
+}
+
+// PKValue returns a value of primary key for that record.
+// Returned interface{} value is never untyped nil.
+func (s *DeviceSetting) PKValue() interface{} {
+	return s.ID
+}
+
+// PKPointer returns a pointer to primary key field for that record.
+// Returned interface{} value is never untyped nil.
+func (s *DeviceSetting) PKPointer() interface{} {
+	return &s.ID
+}
+
+// HasPK returns true if record has non-zero primary key set, false otherwise.
+func (s *DeviceSetting) HasPK() bool {
+	return s.ID != DeviceSettingTable.z[DeviceSettingTable.s.PKFieldIndex]
+}
+
+// SetPK sets record primary key, if possible.
+//
+// Deprecated: prefer direct field assignment where possible: s.ID = pk.
+func (s *DeviceSetting) SetPK(pk interface{}) {
+	reform.SetPK(s, pk)
+}
+
+// check interfaces
+var (
+	_ reform.View   = DeviceSettingTable
+	_ reform.Struct = (*DeviceSetting)(nil)
+	_ reform.Table  = DeviceSettingTable
+	_ reform.Record = (*DeviceSetting)(nil)
+	_ fmt.Stringer  = (*DeviceSetting)(nil)
+)
+
 func init() {
 	parse.AssertUpToDate(&LinkTable.s, new(Link))
 	parse.AssertUpToDate(&LogTable.s, new(Log))
+	parse.AssertUpToDate(&NotificationTable.s, new(Notification))
+	parse.AssertUpToDate(&WebhookTable.s, new(Webhook))
+	parse.AssertUpToDate(&WebhookDeliveryTable.s, new(WebhookDelivery))
+	parse.AssertUpToDate(&MeasurementTable.s, new(Measurement))
+	parse.AssertUpToDate(&CommandTable.s, new(Command))
+	parse.AssertUpToDate(&DeviceSettingTable.s, new(DeviceSetting))
 }
@@ -0,0 +1,222 @@
+// Package alicetest эмулирует callback API Яндекс Диалогов: принимает уведомления об изменении состояния
+// и запросы на повторный discovery, проверяет их как Яндекс и сохраняет для проверок в тестах.
+package alicetest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"sstcloud-alice-gateway/internal/models/alice"
+)
+
+var ErrTimeout = errors.New("timeout waiting for callback")
+
+// Receiver фейковый приемник callback'ов навыка
+type Receiver struct {
+	skillID string
+	token   string
+
+	mu          sync.Mutex
+	states      []alice.State
+	discoveries []alice.Discovery
+	failNext    int
+	// changed закрывается и пересоздается при каждом принятом callback'е
+	changed chan struct{}
+	router  chi.Router
+}
+
+func New(skillID, token string) *Receiver {
+	r := &Receiver{
+		skillID: skillID,
+		token:   token,
+		changed: make(chan struct{}),
+	}
+	router := chi.NewRouter()
+	router.Route("/api/v1/skills/{skill_id}/callback", func(router chi.Router) {
+		router.Use(r.authorize)
+		router.Post("/state", r.state)
+		router.Post("/discovery", r.discovery)
+	})
+	r.router = router
+	return r
+}
+
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.router.ServeHTTP(w, req)
+}
+
+// Server приемник, запущенный на httptest сервере
+type Server struct {
+	*Receiver
+	*httptest.Server
+}
+
+func NewServer(skillID, token string) *Server {
+	r := New(skillID, token)
+	return &Server{
+		Receiver: r,
+		Server:   httptest.NewServer(r),
+	}
+}
+
+// FailNext следующие n callback'ов получат 500, чтобы проверить повторную отправку
+func (r *Receiver) FailNext(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failNext = n
+}
+
+// States принятые уведомления о состоянии в порядке получения
+func (r *Receiver) States() []alice.State {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]alice.State(nil), r.states...)
+}
+
+// Discoveries принятые запросы на повторный discovery в порядке получения
+func (r *Receiver) Discoveries() []alice.Discovery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]alice.Discovery(nil), r.discoveries...)
+}
+
+// WaitState ждет уведомление о состоянии, в котором есть устройство, подходящее под match
+func (r *Receiver) WaitState(timeout time.Duration, match func(userID string, device alice.PayloadStateDevice) bool) (alice.PayloadStateDevice, error) {
+	var result alice.PayloadStateDevice
+	err := r.wait(timeout, func() bool {
+		for _, state := range r.states {
+			for _, device := range state.Payload.Devices {
+				if match(state.Payload.UserID, device) {
+					result = device
+					return true
+				}
+			}
+		}
+		return false
+	})
+	return result, err
+}
+
+// WaitDiscovery ждет запрос на повторный discovery для пользователя
+func (r *Receiver) WaitDiscovery(timeout time.Duration, userID string) error {
+	return r.wait(timeout, func() bool {
+		for _, discovery := range r.discoveries {
+			if discovery.Payload.UserID == userID {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// wait проверяет done под блокировкой после каждого принятого callback'а
+func (r *Receiver) wait(timeout time.Duration, done func() bool) error {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		r.mu.Lock()
+		found := done()
+		changed := r.changed
+		r.mu.Unlock()
+		if found {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-deadline.C:
+			return ErrTimeout
+		}
+	}
+}
+
+func (r *Receiver) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "OAuth "+r.token {
+			writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "invalid oauth token")
+			return
+		}
+		if chi.URLParam(req, "skill_id") != r.skillID {
+			writeError(w, http.StatusNotFound, "NOT_FOUND", "unknown skill")
+			return
+		}
+		r.mu.Lock()
+		fail := r.failNext > 0
+		if fail {
+			r.failNext--
+		}
+		r.mu.Unlock()
+		if fail {
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "injected failure")
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+func (r *Receiver) state(w http.ResponseWriter, req *http.Request) {
+	var body alice.State
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+	if body.TS == 0 || body.Payload.UserID == "" || len(body.Payload.Devices) == 0 {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "ts, payload.user_id and payload.devices are required")
+		return
+	}
+	for _, device := range body.Payload.Devices {
+		if device.ID == "" || len(device.Capabilities)+len(device.Properties) == 0 {
+			writeError(w, http.StatusBadRequest, "BAD_REQUEST", "device id and state are required")
+			return
+		}
+	}
+	r.record(func() {
+		r.states = append(r.states, body)
+	})
+	writeAccepted(w)
+}
+
+func (r *Receiver) discovery(w http.ResponseWriter, req *http.Request) {
+	var body alice.Discovery
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+	if body.TS == 0 || body.Payload.UserID == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "ts and payload.user_id are required")
+		return
+	}
+	r.record(func() {
+		r.discoveries = append(r.discoveries, body)
+	})
+	writeAccepted(w)
+}
+
+func (r *Receiver) record(add func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	add()
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+func writeAccepted(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"status":        "error",
+		"error_code":    code,
+		"error_message": message,
+	})
+}
@@ -4,69 +4,357 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/zerolog/log"
 
-	"sstcloud-alice-gateway/internal/device_provider"
 	"sstcloud-alice-gateway/internal/mappers"
 	"sstcloud-alice-gateway/internal/models/alice"
+	storageModels "sstcloud-alice-gateway/internal/models/storage"
+	"sstcloud-alice-gateway/internal/notifier"
 )
 
+const queueTick = time.Second
+
+type Store interface {
+	Notifications(ctx context.Context) ([]*storageModels.Notification, error)
+	SaveNotification(ctx context.Context, notification *storageModels.Notification) error
+	DeleteNotification(ctx context.Context, userID string) error
+}
+
 type client struct {
-	config          Config
-	callbackAddress string
-	client          *http.Client
+	config           Config
+	callbackAddress  string
+	discoveryAddress string
+	client           *http.Client
+	store            Store
+	queue            map[string]*outboxItem
+	queueM           sync.Mutex
+	// seq номер снимка очереди, растет под queueM
+	seq uint64
+	// persisted номер последнего записанного снимка пользователя, под persistM
+	persisted  map[string]uint64
+	persistM   sync.Mutex
+	cancelFunc context.CancelFunc
+	sent       uint64
+	failures   uint64
+	dropped    uint64
 }
 
-func New(config Config) *client {
-	return &client{
+func New(config Config, store Store) *client {
+	result := &client{
 		config: config,
 		client: &http.Client{
 			Timeout: config.RequestTimeout,
 		},
-		callbackAddress: config.Address + "/api/v1/skills/" + config.SkillID + "/callback/state",
+		callbackAddress:  config.Address + "/api/v1/skills/" + config.SkillID + "/callback/state",
+		discoveryAddress: config.Address + "/api/v1/skills/" + config.SkillID + "/callback/discovery",
+		queue:            map[string]*outboxItem{},
+		persisted:        map[string]uint64{},
+	}
+	if config.Persist {
+		result.store = store
+	}
+	return result
+}
+
+func (c *client) Run(ctx context.Context, ready func()) error {
+	ctx, c.cancelFunc = context.WithCancel(ctx)
+	defer c.cancelFunc()
+	if err := c.restore(ctx); err != nil {
+		return err
+	}
+	ready()
+	ticker := time.NewTicker(queueTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			c.deliver(ctx)
+		}
+	}
+}
+
+func (c *client) Shutdown(ctx context.Context) error {
+	if c.cancelFunc != nil {
+		c.cancelFunc()
+	}
+	return nil
+}
+
+func (c *client) NotifyDevicesChanged(ctx context.Context, userID string, changes []notifier.DeviceChange) error {
+	c.queueM.Lock()
+	item := c.pending(userID)
+	for _, change := range changes {
+		for _, state := range mappers.DeviceToAliceState(change.Device, change.Changes) {
+			item.add(state)
+		}
+	}
+	snapshot := c.snapshot(ctx, userID, item)
+	c.queueM.Unlock()
+	c.persist(ctx, snapshot)
+	return nil
+}
+
+func (c *client) NotifyDevicesListChanged(ctx context.Context, userID string) error {
+	c.queueM.Lock()
+	item := c.pending(userID)
+	item.discovery = true
+	snapshot := c.snapshot(ctx, userID, item)
+	c.queueM.Unlock()
+	c.persist(ctx, snapshot)
+	return nil
+}
+
+// pending возвращает накапливаемые изменения пользователя, вызывается под queueM.
+func (c *client) pending(userID string) *outboxItem {
+	item, exists := c.queue[userID]
+	if !exists {
+		item = newOutboxItem(time.Now().Add(c.config.BatchWindow))
+		c.queue[userID] = item
+	}
+	return item
+}
+
+func (c *client) Stats() notifier.QueueStats {
+	c.queueM.Lock()
+	defer c.queueM.Unlock()
+	result := notifier.QueueStats{
+		Users:    len(c.queue),
+		Sent:     atomic.LoadUint64(&c.sent),
+		Failures: atomic.LoadUint64(&c.failures),
+		Dropped:  atomic.LoadUint64(&c.dropped),
+	}
+	for _, item := range c.queue {
+		result.Depth += len(item.devices)
+		if item.discovery {
+			result.Depth++
+		}
 	}
+	return result
 }
 
-func (c *client) NotifyDevicesChanged(ctx context.Context, house *device_provider.House, device []*device_provider.Device) error {
+func (c *client) deliver(ctx context.Context) {
+	now := time.Now()
+	due := map[string]*outboxItem{}
+	c.queueM.Lock()
+	for userID, item := range c.queue {
+		if item.nextAttemptAt.After(now) {
+			continue
+		}
+		due[userID] = item
+		delete(c.queue, userID)
+	}
+	c.queueM.Unlock()
+
+	for userID, item := range due {
+		logger := log.Ctx(ctx).With().Str("user_id", userID).Int("attempt", item.attempts+1).Logger()
+		ctx := logger.WithContext(ctx)
+		err := c.send(ctx, userID, item)
+		if err == nil {
+			atomic.AddUint64(&c.sent, 1)
+			c.delivered(ctx, userID)
+			continue
+		}
+		atomic.AddUint64(&c.failures, 1)
+		if isPermanent(err) {
+			logger.Error().Err(err).Msg("Callback rejected, dropping state")
+			atomic.AddUint64(&c.dropped, 1)
+			c.delivered(ctx, userID)
+			continue
+		}
+		item.attempts++
+		item.lastError = err.Error()
+		item.nextAttemptAt = time.Now().Add(c.backoff(item.attempts))
+		logger.Warn().Err(err).Time("next_attempt_at", item.nextAttemptAt).Msg("Failed send state callback, will retry")
+		c.requeue(ctx, userID, item)
+	}
+}
+
+// delivered удаляет сохраненную очередь пользователя, если за время отправки не накопились новые изменения.
+func (c *client) delivered(ctx context.Context, userID string) {
+	c.queueM.Lock()
+	// очередь пуста - снимок без уведомления удалит сохраненное
+	snapshot := c.snapshot(ctx, userID, c.queue[userID])
+	c.queueM.Unlock()
+	c.persist(ctx, snapshot)
+}
+
+// requeue возвращает неотправленное состояние в очередь, изменения пришедшие за время отправки имеют приоритет.
+func (c *client) requeue(ctx context.Context, userID string, item *outboxItem) {
+	c.queueM.Lock()
+	if newer, exists := c.queue[userID]; exists {
+		for _, state := range newer.devices {
+			item.add(state)
+		}
+		item.discovery = item.discovery || newer.discovery
+	}
+	c.queue[userID] = item
+	snapshot := c.snapshot(ctx, userID, item)
+	c.queueM.Unlock()
+	c.persist(ctx, snapshot)
+}
+
+func (c *client) backoff(attempts int) time.Duration {
+	result := c.config.RetryMin
+	for i := 1; i < attempts && result < c.config.RetryMax; i++ {
+		result *= 2
+	}
+	if result > c.config.RetryMax {
+		return c.config.RetryMax
+	}
+	return result
+}
+
+type outboxSnapshot struct {
+	seq    uint64
+	userID string
+	// notification nil - очередь пользователя пуста и сохраненное уведомление нужно удалить
+	notification *storageModels.Notification
+}
+
+// snapshot копирует очередь пользователя для записи в бд, вызывается под queueM.
+// Сама запись делается в persist уже без блокировки, чтобы не задерживать очередь на время запроса к бд.
+func (c *client) snapshot(ctx context.Context, userID string, item *outboxItem) *outboxSnapshot {
+	if c.store == nil {
+		return nil
+	}
+	c.seq++
+	result := &outboxSnapshot{seq: c.seq, userID: userID}
+	if item == nil {
+		return result
+	}
+	blob, err := json.Marshal(item.states())
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Failed marshal notification")
+		return nil
+	}
+	result.notification = &storageModels.Notification{
+		UserID:        userID,
+		Payload:       string(blob),
+		Discovery:     item.discovery,
+		Attempts:      item.attempts,
+		NextAttemptAt: item.nextAttemptAt,
+		LastError:     item.lastError,
+	}
+	return result
+}
+
+// persist записывает снимок, если более новый снимок пользователя еще не записан
+func (c *client) persist(ctx context.Context, snapshot *outboxSnapshot) {
+	if snapshot == nil {
+		return
+	}
+	c.persistM.Lock()
+	defer c.persistM.Unlock()
+	if c.persisted[snapshot.userID] > snapshot.seq {
+		return
+	}
+	c.persisted[snapshot.userID] = snapshot.seq
 	logger := log.Ctx(ctx)
-	devices := make([]alice.PayloadStateDevice, 0, len(device))
-	for _, dev := range device {
-		objs := mappers.DeviceToAlice(dev)
-		for _, obj := range objs {
-			if len(obj.Properties) == 0 && len(obj.Capabilities) == 0 {
-				continue
-			}
-			props := make([]alice.PayloadStateDeviceProperties, 0, len(obj.Properties))
-			for _, prop := range obj.Properties {
-				props = append(props, alice.PayloadStateDeviceProperties{
-					Type:  prop.Type,
-					State: prop.State,
-				})
-			}
-			devices = append(devices, alice.PayloadStateDevice{
-				ID:           obj.ID,
-				Properties:   props,
-				Capabilities: obj.Capabilities,
-			})
+	if snapshot.notification == nil {
+		if err := c.store.DeleteNotification(ctx, snapshot.userID); err != nil {
+			logger.Error().Err(err).Msg("Failed delete delivered notification")
 		}
+		return
 	}
-	blob, err := json.Marshal(alice.State{
+	if err := c.store.SaveNotification(ctx, snapshot.notification); err != nil {
+		logger.Error().Err(err).Msg("Failed save notification")
+	}
+}
+
+func (c *client) restore(ctx context.Context) error {
+	if c.store == nil {
+		return nil
+	}
+	logger := log.Ctx(ctx)
+	notifications, err := c.store.Notifications(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed load notifications")
+		return err
+	}
+	c.queueM.Lock()
+	defer c.queueM.Unlock()
+	for _, notification := range notifications {
+		var states []alice.PayloadStateDevice
+		if err := json.Unmarshal([]byte(notification.Payload), &states); err != nil {
+			logger.Error().Err(err).Str("user_id", notification.UserID).Msg("Failed unmarshal notification, skip")
+			continue
+		}
+		item := newOutboxItem(notification.NextAttemptAt)
+		item.attempts = notification.Attempts
+		item.lastError = notification.LastError
+		item.discovery = notification.Discovery
+		for _, state := range states {
+			item.add(state)
+		}
+		c.queue[notification.UserID] = item
+	}
+	logger.Info().Int("users", len(notifications)).Msg("Notification queue restored")
+	return nil
+}
+
+type statusError struct {
+	status string
+	code   int
+	body   []byte
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status %s: %s", e.status, e.body)
+}
+
+// isPermanent сообщает, что повтор запроса не поможет: Яндекс отверг сам запрос.
+func isPermanent(err error) bool {
+	statusErr, ok := err.(*statusError)
+	if !ok {
+		return false
+	}
+	return statusErr.code >= 400 && statusErr.code < 500 && statusErr.code != http.StatusTooManyRequests
+}
+
+// send отправляет сначала запрос на повторный discovery, затем состояние устройств.
+// Успешно отправленная часть из item убирается, чтобы при повторе не дублироваться.
+func (c *client) send(ctx context.Context, userID string, item *outboxItem) error {
+	if item.discovery {
+		if err := c.post(ctx, c.discoveryAddress, alice.Discovery{
+			TS: time.Now().Unix(),
+			Payload: alice.PayloadDiscovery{
+				UserID: userID,
+			},
+		}); err != nil {
+			return err
+		}
+		item.discovery = false
+	}
+	if len(item.devices) == 0 {
+		return nil
+	}
+	return c.post(ctx, c.callbackAddress, alice.State{
 		TS: time.Now().Unix(),
 		Payload: alice.PayloadState{
-			UserID:  house.UserID,
-			Devices: devices,
+			UserID:  userID,
+			Devices: item.states(),
 		},
 	})
+}
+
+func (c *client) post(ctx context.Context, address string, body interface{}) error {
+	logger := log.Ctx(ctx)
+	blob, err := json.Marshal(body)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed marshal body")
 		return err
 	}
-	logger.Trace().Str("url", c.callbackAddress).Bytes("blob", blob).Msg("Prepare request body")
-	req, err := http.NewRequest(http.MethodPost, c.callbackAddress, bytes.NewReader(blob))
+	logger.Trace().Str("url", address).Bytes("blob", blob).Msg("Prepare request body")
+	req, err := http.NewRequest(http.MethodPost, address, bytes.NewReader(blob))
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed create request object")
 		return err
@@ -79,13 +367,14 @@ func (c *client) NotifyDevicesChanged(ctx context.Context, house *device_provide
 		logger.Error().Err(err).Msg("Failed make request")
 		return err
 	}
-	if resp.StatusCode != http.StatusOK {
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
 		blob, err := io.ReadAll(resp.Body)
 		if err != nil {
 			logger.Error().Err(err).Msg("Failed read body")
 		}
 		logger.Error().Str("status", resp.Status).Bytes("response", blob).Msg("status")
-		return nil
+		return &statusError{status: resp.Status, code: resp.StatusCode, body: blob}
 	}
 	logger.Debug().Str("status", resp.Status).Msg("status")
 
@@ -0,0 +1,258 @@
+package alice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"sstcloud-alice-gateway/internal/models/alice"
+	storageModels "sstcloud-alice-gateway/internal/models/storage"
+)
+
+type memoryStore struct {
+	mu            sync.Mutex
+	notifications map[string]*storageModels.Notification
+}
+
+func (s *memoryStore) Notifications(context.Context) ([]*storageModels.Notification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var result []*storageModels.Notification
+	for _, notification := range s.notifications {
+		result = append(result, notification)
+	}
+	return result, nil
+}
+
+func (s *memoryStore) SaveNotification(_ context.Context, notification *storageModels.Notification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifications[notification.UserID] = notification
+	return nil
+}
+
+func (s *memoryStore) DeleteNotification(_ context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.notifications, userID)
+	return nil
+}
+
+func (s *memoryStore) get(userID string) *storageModels.Notification {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.notifications[userID]
+}
+
+// newTestClient клиент, который отправляет callback'и на handler
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*client, *memoryStore) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	store := &memoryStore{notifications: map[string]*storageModels.Notification{}}
+	c := New(Config{
+		SkillID:        "skill",
+		Address:        srv.URL,
+		RequestTimeout: time.Second,
+		RetryMin:       time.Second,
+		RetryMax:       8 * time.Second,
+		Persist:        true,
+	}, store)
+	return c, store
+}
+
+// enqueue кладет состояние в очередь пользователя так, чтобы оно ушло при следующем deliver
+func enqueue(c *client, userID string, discovery bool, states ...alice.PayloadStateDevice) {
+	c.queueM.Lock()
+	defer c.queueM.Unlock()
+	item := c.pending(userID)
+	for _, state := range states {
+		item.add(state)
+	}
+	item.discovery = item.discovery || discovery
+	item.nextAttemptAt = time.Time{}
+}
+
+func TestBackoff(t *testing.T) {
+	c := New(Config{RetryMin: time.Second, RetryMax: 8 * time.Second}, nil)
+	for attempts, want := range map[int]time.Duration{
+		1:  time.Second,
+		2:  2 * time.Second,
+		3:  4 * time.Second,
+		4:  8 * time.Second,
+		5:  8 * time.Second,
+		20: 8 * time.Second,
+	} {
+		if got := c.backoff(attempts); got != want {
+			t.Fatalf("attempt %d: got %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+func TestIsPermanent(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{err: &statusError{code: http.StatusBadRequest}, want: true},
+		{err: &statusError{code: http.StatusUnauthorized}, want: true},
+		{err: &statusError{code: http.StatusNotFound}, want: true},
+		{err: &statusError{code: http.StatusTooManyRequests}},
+		{err: &statusError{code: http.StatusInternalServerError}},
+		{err: &statusError{code: http.StatusBadGateway}},
+		{err: errors.New("connection refused")},
+		{err: context.DeadlineExceeded},
+	}
+	for _, tt := range tests {
+		if got := isPermanent(tt.err); got != tt.want {
+			t.Fatalf("isPermanent(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestDeliverStatuses(t *testing.T) {
+	tests := []struct {
+		status  int
+		retried bool
+	}{
+		{status: http.StatusOK},
+		{status: http.StatusAccepted},
+		{status: http.StatusBadRequest},
+		{status: http.StatusTooManyRequests, retried: true},
+		{status: http.StatusInternalServerError, retried: true},
+		{status: http.StatusServiceUnavailable, retried: true},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			c, store := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			})
+			enqueue(c, "user", false, alice.PayloadStateDevice{ID: "1_2", Properties: []alice.PayloadStateDeviceProperties{temperature(23)}})
+			started := time.Now()
+			c.deliver(context.Background())
+
+			item, queued := c.queue["user"]
+			if queued != tt.retried {
+				t.Fatalf("queued %v, want %v", queued, tt.retried)
+			}
+			stats := c.Stats()
+			if !tt.retried {
+				if store.get("user") != nil {
+					t.Fatal("delivered or rejected state must be removed from store")
+				}
+				if tt.status >= 400 && stats.Dropped != 1 {
+					t.Fatalf("rejected state must be counted as dropped: %+v", stats)
+				}
+				return
+			}
+			if item.attempts != 1 || item.lastError == "" || len(item.devices) != 1 {
+				t.Fatalf("unexpected requeued item %+v", item)
+			}
+			if item.nextAttemptAt.Before(started.Add(c.config.RetryMin)) {
+				t.Fatalf("next attempt %v is earlier than backoff", item.nextAttemptAt)
+			}
+			if saved := store.get("user"); saved == nil || saved.Attempts != 1 || saved.LastError == "" {
+				t.Fatalf("requeued state must be persisted, got %+v", saved)
+			}
+			if stats.Failures != 1 || stats.Dropped != 0 {
+				t.Fatalf("unexpected stats %+v", stats)
+			}
+		})
+	}
+}
+
+func TestDeliverBackoffGrows(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	enqueue(c, "user", false, alice.PayloadStateDevice{ID: "1_2", Properties: []alice.PayloadStateDeviceProperties{temperature(23)}})
+	for attempt, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 8 * time.Second} {
+		started := time.Now()
+		c.deliver(context.Background())
+		item := c.queue["user"]
+		if item.attempts != attempt+1 {
+			t.Fatalf("attempts %d, want %d", item.attempts, attempt+1)
+		}
+		if delay := item.nextAttemptAt.Sub(started); delay < want || delay > want+time.Second {
+			t.Fatalf("attempt %d: delay %v, want %v", item.attempts, delay, want)
+		}
+		item.nextAttemptAt = time.Time{}
+	}
+}
+
+func TestRequeueMergesNewerStates(t *testing.T) {
+	var c *client
+	var discoveries int
+	c, store := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/discovery") {
+			discoveries++
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		// пока запрос в пути, появляются более свежие изменения
+		c.queueM.Lock()
+		item := c.pending("user")
+		item.add(alice.PayloadStateDevice{ID: "1_2", Properties: []alice.PayloadStateDeviceProperties{temperature(25)}})
+		item.add(alice.PayloadStateDevice{ID: "1_3", Capabilities: []alice.PayloadStateDeviceCapabilities{onOff(true)}})
+		c.queueM.Unlock()
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	enqueue(c, "user", true,
+		alice.PayloadStateDevice{ID: "1_2", Properties: []alice.PayloadStateDeviceProperties{temperature(23)}},
+		alice.PayloadStateDevice{ID: "1_4", Capabilities: []alice.PayloadStateDeviceCapabilities{onOff(false)}},
+	)
+	c.deliver(context.Background())
+
+	item := c.queue["user"]
+	if item == nil || item.attempts != 1 {
+		t.Fatalf("unexpected item %+v", item)
+	}
+	// discovery уже доставлен и не повторяется
+	if discoveries != 1 || item.discovery {
+		t.Fatalf("discovery sent %d times, pending %v", discoveries, item.discovery)
+	}
+	states := item.states()
+	if len(states) != 3 || states[0].ID != "1_2" || states[1].ID != "1_3" || states[2].ID != "1_4" {
+		t.Fatalf("unexpected states %+v", states)
+	}
+	if value := states[0].Properties[0].State.Value; value != 25.0 {
+		t.Fatalf("newer state must win, got %v", value)
+	}
+	if saved := store.get("user"); saved == nil || !strings.Contains(saved.Payload, `"1_3"`) {
+		t.Fatalf("merged state must be persisted, got %+v", saved)
+	}
+}
+
+func TestPersistSkipsStaleSnapshot(t *testing.T) {
+	c, store := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {})
+	ctx := context.Background()
+	item := newOutboxItem(time.Time{})
+	item.add(alice.PayloadStateDevice{ID: "1_2", Properties: []alice.PayloadStateDeviceProperties{temperature(23)}})
+
+	c.queueM.Lock()
+	older := c.snapshot(ctx, "user", item)
+	newer := c.snapshot(ctx, "user", nil)
+	c.queueM.Unlock()
+	if older.seq >= newer.seq {
+		t.Fatalf("snapshot seq must grow: %d, %d", older.seq, newer.seq)
+	}
+	// записи пришли в обратном порядке: удаление новее и не должно быть перезаписано
+	c.persist(ctx, newer)
+	c.persist(ctx, older)
+	if saved := store.get("user"); saved != nil {
+		t.Fatalf("stale snapshot was persisted: %+v", saved)
+	}
+
+	c.queueM.Lock()
+	latest := c.snapshot(ctx, "user", item)
+	c.queueM.Unlock()
+	c.persist(ctx, latest)
+	if saved := store.get("user"); saved == nil {
+		t.Fatal("latest snapshot was not persisted")
+	}
+}
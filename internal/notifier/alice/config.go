@@ -1,12 +1,32 @@
 package alice
 
 import (
+	"errors"
 	"time"
 )
 
 type Config struct {
-	SkillID        string        `env:"YANDEX_ALICE_SKILL_ID,required"`
+	SkillID        string        `env:"YANDEX_ALICE_SKILL_ID"`
 	Address        string        `env:"YANDEX_ALICE_ADDRESS,default=https://dialogs.yandex.net"`
 	RequestTimeout time.Duration `env:"YANDEX_ALICE_TIMEOUT,default=5s"`
-	OAuth2Token    string        `env:"YANDEX_OAUTH2_TOKEN,required"`
+	OAuth2Token    string        `env:"YANDEX_OAUTH2_TOKEN"`
+	// BatchWindow время, в течение которого изменения пользователя копятся перед отправкой одним callback'ом
+	BatchWindow time.Duration `env:"YANDEX_ALICE_BATCH_WINDOW,default=2s"`
+	// RetryMin задержка перед первым повтором неудачного callback'а, дальше удваивается
+	RetryMin time.Duration `env:"YANDEX_ALICE_RETRY_MIN,default=5s"`
+	// RetryMax максимальная задержка между повторами
+	RetryMax time.Duration `env:"YANDEX_ALICE_RETRY_MAX,default=5m"`
+	// Persist сохранять очередь в бд, чтобы не терять изменения при перезапуске
+	Persist bool `env:"YANDEX_ALICE_QUEUE_PERSIST"`
+}
+
+// Validate проверяет обязательные параметры, когда уведомления Алисы включены.
+func (c Config) Validate() error {
+	if c.SkillID == "" {
+		return errors.New("YANDEX_ALICE_SKILL_ID is required")
+	}
+	if c.OAuth2Token == "" {
+		return errors.New("YANDEX_OAUTH2_TOKEN is required")
+	}
+	return nil
 }
@@ -0,0 +1,72 @@
+package alice
+
+import (
+	"sort"
+	"time"
+
+	"sstcloud-alice-gateway/internal/models/alice"
+)
+
+// outboxItem недоставленные изменения одного пользователя, по одному состоянию на устройство,
+// и признак того, что Алисе нужно перечитать список устройств.
+type outboxItem struct {
+	devices       map[string]alice.PayloadStateDevice
+	discovery     bool
+	attempts      int
+	nextAttemptAt time.Time
+	lastError     string
+}
+
+func newOutboxItem(nextAttemptAt time.Time) *outboxItem {
+	return &outboxItem{
+		devices:       map[string]alice.PayloadStateDevice{},
+		nextAttemptAt: nextAttemptAt,
+	}
+}
+
+func (i *outboxItem) add(state alice.PayloadStateDevice) {
+	i.devices[state.ID] = mergeState(i.devices[state.ID], state)
+}
+
+func (i *outboxItem) states() []alice.PayloadStateDevice {
+	result := make([]alice.PayloadStateDevice, 0, len(i.devices))
+	for _, state := range i.devices {
+		result = append(result, state)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// mergeState накладывает более свежее состояние устройства на еще не отправленное.
+func mergeState(prev, next alice.PayloadStateDevice) alice.PayloadStateDevice {
+	if prev.ID == "" {
+		return next
+	}
+	for _, capability := range next.Capabilities {
+		replaced := false
+		for i := range prev.Capabilities {
+			if prev.Capabilities[i].Type == capability.Type {
+				prev.Capabilities[i] = capability
+				replaced = true
+			}
+		}
+		if !replaced {
+			prev.Capabilities = append(prev.Capabilities, capability)
+		}
+	}
+	for _, property := range next.Properties {
+		replaced := false
+		for i := range prev.Properties {
+			if prev.Properties[i].State.Instance == property.State.Instance {
+				prev.Properties[i] = property
+				replaced = true
+			}
+		}
+		if !replaced {
+			prev.Properties = append(prev.Properties, property)
+		}
+	}
+	return prev
+}
@@ -0,0 +1,109 @@
+package alice
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"sstcloud-alice-gateway/internal/device_provider"
+	"sstcloud-alice-gateway/internal/mappers"
+	"sstcloud-alice-gateway/internal/models/alice"
+)
+
+func onOff(value bool) alice.PayloadStateDeviceCapabilities {
+	return alice.PayloadStateDeviceCapabilities{
+		Type:  alice.CapabilityTypeOnOff,
+		State: alice.CapabilityOnOffState{Instance: alice.CapabilityOnOffInstanceOn, Value: value},
+	}
+}
+
+func temperature(value float64) alice.PayloadStateDeviceProperties {
+	return alice.PayloadStateDeviceProperties{
+		Type:  alice.PropertyTypeFloat,
+		State: alice.PayloadStateDevicePropertiesState{Instance: alice.PropertyParameterInstanceTemperature, Value: value},
+	}
+}
+
+func TestMergeState(t *testing.T) {
+	tests := []struct {
+		name string
+		prev alice.PayloadStateDevice
+		next alice.PayloadStateDevice
+		want alice.PayloadStateDevice
+	}{
+		{
+			name: "nothing pending",
+			next: alice.PayloadStateDevice{ID: "1_2", Capabilities: []alice.PayloadStateDeviceCapabilities{onOff(true)}},
+			want: alice.PayloadStateDevice{ID: "1_2", Capabilities: []alice.PayloadStateDeviceCapabilities{onOff(true)}},
+		},
+		{
+			name: "newer capability wins",
+			prev: alice.PayloadStateDevice{ID: "1_2", Capabilities: []alice.PayloadStateDeviceCapabilities{onOff(true)}},
+			next: alice.PayloadStateDevice{ID: "1_2", Capabilities: []alice.PayloadStateDeviceCapabilities{onOff(false)}},
+			want: alice.PayloadStateDevice{ID: "1_2", Capabilities: []alice.PayloadStateDeviceCapabilities{onOff(false)}},
+		},
+		{
+			name: "capability and property are kept together",
+			prev: alice.PayloadStateDevice{ID: "1_2", Capabilities: []alice.PayloadStateDeviceCapabilities{onOff(true)}},
+			next: alice.PayloadStateDevice{ID: "1_2", Properties: []alice.PayloadStateDeviceProperties{temperature(23)}},
+			want: alice.PayloadStateDevice{
+				ID:           "1_2",
+				Capabilities: []alice.PayloadStateDeviceCapabilities{onOff(true)},
+				Properties:   []alice.PayloadStateDeviceProperties{temperature(23)},
+			},
+		},
+		{
+			name: "newer property wins",
+			prev: alice.PayloadStateDevice{ID: "1_2", Properties: []alice.PayloadStateDeviceProperties{temperature(23)}},
+			next: alice.PayloadStateDevice{ID: "1_2", Properties: []alice.PayloadStateDeviceProperties{temperature(24)}},
+			want: alice.PayloadStateDevice{ID: "1_2", Properties: []alice.PayloadStateDeviceProperties{temperature(24)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeState(tt.prev, tt.next); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutboxItemStates(t *testing.T) {
+	item := newOutboxItem(time.Time{})
+	item.add(alice.PayloadStateDevice{ID: "1_3", Capabilities: []alice.PayloadStateDeviceCapabilities{onOff(true)}})
+	item.add(alice.PayloadStateDevice{ID: "1_2", Capabilities: []alice.PayloadStateDeviceCapabilities{onOff(true)}})
+	item.add(alice.PayloadStateDevice{ID: "1_3", Capabilities: []alice.PayloadStateDeviceCapabilities{onOff(false)}})
+	states := item.states()
+	if len(states) != 2 || states[0].ID != "1_2" || states[1].ID != "1_3" {
+		t.Fatalf("expected one state per device sorted by id, got %+v", states)
+	}
+	if !reflect.DeepEqual(states[1].Capabilities, []alice.PayloadStateDeviceCapabilities{onOff(false)}) {
+		t.Fatalf("latest state must win, got %+v", states[1])
+	}
+}
+
+func TestOutboxItemKeepsSensors(t *testing.T) {
+	device := &device_provider.Device{
+		House:      &device_provider.House{ID: 1, Layout: device_provider.LayoutCombined},
+		ID:         2,
+		Connected:  true,
+		Tempometer: device_provider.Tempometer{DegreesFloor: device_provider.Degrees(23), DegreesAir: device_provider.Degrees(21)},
+	}
+	changed := device_provider.ChangedDegreesAir | device_provider.ChangedDegreesFloor
+	item := newOutboxItem(time.Time{})
+	for _, state := range mappers.DeviceToAliceState(device, changed) {
+		item.add(state)
+	}
+	device.Tempometer.DegreesAir = device_provider.Degrees(22)
+	for _, state := range mappers.DeviceToAliceState(device, device_provider.ChangedDegreesAir) {
+		item.add(state)
+	}
+	// пол - свойство термостата, воздух - отдельный датчик, поэтому при слиянии температуры не затирают друг друга
+	want := []alice.PayloadStateDevice{
+		{ID: "1_2", Properties: []alice.PayloadStateDeviceProperties{temperature(23)}},
+		{ID: "1_2_air", Properties: []alice.PayloadStateDeviceProperties{temperature(22)}},
+	}
+	if states := item.states(); !reflect.DeepEqual(states, want) {
+		t.Fatalf("got %+v, want %+v", states, want)
+	}
+}
@@ -0,0 +1,75 @@
+package broadcast
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"sstcloud-alice-gateway/internal/notifier"
+)
+
+// subscriberBuffer сколько событий может отстать подписчик, прежде чем они начнут теряться
+const subscriberBuffer = 16
+
+type Event struct {
+	Changes     []notifier.DeviceChange
+	ListChanged bool
+}
+
+// broadcaster раздает изменения устройств подписчикам в рамках процесса, например потоку событий REST.
+type broadcaster struct {
+	subscribers  map[string]map[chan Event]struct{}
+	subscribersM sync.Mutex
+}
+
+func New() *broadcaster {
+	return &broadcaster{
+		subscribers: map[string]map[chan Event]struct{}{},
+	}
+}
+
+// Subscribe возвращает канал событий пользователя и функцию отписки, которую нужно вызвать обязательно.
+func (b *broadcaster) Subscribe(userID string) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	b.subscribersM.Lock()
+	defer b.subscribersM.Unlock()
+	if _, exists := b.subscribers[userID]; !exists {
+		b.subscribers[userID] = map[chan Event]struct{}{}
+	}
+	b.subscribers[userID][ch] = struct{}{}
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.subscribersM.Lock()
+			defer b.subscribersM.Unlock()
+			delete(b.subscribers[userID], ch)
+			if len(b.subscribers[userID]) == 0 {
+				delete(b.subscribers, userID)
+			}
+			close(ch)
+		})
+	}
+}
+
+func (b *broadcaster) NotifyDevicesChanged(ctx context.Context, userID string, changes []notifier.DeviceChange) error {
+	b.publish(ctx, userID, Event{Changes: changes})
+	return nil
+}
+
+func (b *broadcaster) NotifyDevicesListChanged(ctx context.Context, userID string) error {
+	b.publish(ctx, userID, Event{ListChanged: true})
+	return nil
+}
+
+func (b *broadcaster) publish(ctx context.Context, userID string, event Event) {
+	b.subscribersM.Lock()
+	defer b.subscribersM.Unlock()
+	for ch := range b.subscribers[userID] {
+		select {
+		case ch <- event:
+		default:
+			log.Ctx(ctx).Warn().Str("user_id", userID).Msg("Subscriber is too slow, event dropped")
+		}
+	}
+}
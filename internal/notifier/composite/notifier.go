@@ -0,0 +1,31 @@
+package composite
+
+import (
+	"context"
+	"errors"
+
+	"sstcloud-alice-gateway/internal/notifier"
+)
+
+// notifiers рассылает изменения во все подключенные бэкенды, ошибка одного не мешает остальным.
+type notifiers []notifier.Notifier
+
+func New(children ...notifier.Notifier) notifier.Notifier {
+	return notifiers(children)
+}
+
+func (n notifiers) NotifyDevicesChanged(ctx context.Context, userID string, changes []notifier.DeviceChange) error {
+	errs := make([]error, 0, len(n))
+	for _, child := range n {
+		errs = append(errs, child.NotifyDevicesChanged(ctx, userID, changes))
+	}
+	return errors.Join(errs...)
+}
+
+func (n notifiers) NotifyDevicesListChanged(ctx context.Context, userID string) error {
+	errs := make([]error, 0, len(n))
+	for _, child := range n {
+		errs = append(errs, child.NotifyDevicesListChanged(ctx, userID))
+	}
+	return errors.Join(errs...)
+}
@@ -6,6 +6,23 @@ import (
 	"sstcloud-alice-gateway/internal/device_provider"
 )
 
+type DeviceChange struct {
+	Device  *device_provider.Device
+	Changes device_provider.Changes
+}
+
 type Notifier interface {
-	NotifyDevicesChanged(ctx context.Context, house *device_provider.House, device []*device_provider.Device) error
+	NotifyDevicesChanged(ctx context.Context, userID string, changes []DeviceChange) error
+	// NotifyDevicesListChanged сообщает, что у пользователя появились или пропали устройства
+	NotifyDevicesListChanged(ctx context.Context, userID string) error
+}
+
+type QueueStats struct {
+	// Depth количество устройств, состояние которых еще не доставлено
+	Depth int `json:"depth"`
+	// Users количество пользователей с недоставленными изменениями
+	Users    int    `json:"users"`
+	Sent     uint64 `json:"sent"`
+	Failures uint64 `json:"failures"`
+	Dropped  uint64 `json:"dropped"`
 }
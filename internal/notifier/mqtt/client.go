@@ -0,0 +1,247 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pahomqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/rs/zerolog/log"
+
+	"sstcloud-alice-gateway/internal/device_provider"
+	"sstcloud-alice-gateway/internal/notifier"
+)
+
+const disconnectQuiesce = 250 // ms
+
+const (
+	topicState            = "state"
+	topicPower            = "power"
+	topicConnected        = "connected"
+	topicSetpoint         = "setpoint"
+	topicFloorTemperature = "floor_temperature"
+	topicAirTemperature   = "air_temperature"
+	topicMode             = "mode"
+)
+
+const (
+	payloadOn      = "ON"
+	payloadOff     = "OFF"
+	payloadOnline  = "online"
+	payloadOffline = "offline"
+	payloadHeat    = "heat"
+)
+
+type client struct {
+	config      Config
+	client      pahomqtt.Client
+	cancelFunc  context.CancelFunc
+	ctx         context.Context
+	provider    DeviceProvider
+	discovered  map[string]map[deviceKey]struct{}
+	discoveredM sync.Mutex
+	// queue сообщения публикуются по очереди в фоне, чтобы недоступный брокер не задерживал опрос устройств
+	queue chan message
+}
+
+type message struct {
+	ctx     context.Context
+	topic   string
+	retain  bool
+	payload []byte
+}
+
+type DeviceProvider interface {
+	Devices(userID string) []*device_provider.Device
+	Refresh(userID string, houseID int) bool
+}
+
+type deviceState struct {
+	Name             string                      `json:"name"`
+	Model            string                      `json:"model"`
+	House            string                      `json:"house"`
+	Enabled          bool                        `json:"enabled"`
+	Connected        bool                        `json:"connected"`
+	Setpoint         device_provider.Temperature `json:"setpoint"`
+	FloorTemperature device_provider.Temperature `json:"floor_temperature"`
+	AirTemperature   device_provider.Temperature `json:"air_temperature"`
+	UpdatedAt        time.Time                   `json:"updated_at"`
+}
+
+func New(config Config) *client {
+	opts := pahomqtt.NewClientOptions().
+		AddBroker(config.Broker).
+		SetClientID(config.ClientID).
+		SetUsername(config.Username).
+		SetPassword(config.Password).
+		SetConnectTimeout(config.Timeout).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		// команды ходят в SST и могут выполняться долго, не задерживаем ими остальные сообщения
+		SetOrderMatters(false)
+	result := &client{
+		config:     config,
+		discovered: map[string]map[deviceKey]struct{}{},
+		queue:      make(chan message, config.QueueSize),
+	}
+	opts.SetOnConnectHandler(result.onConnect)
+	result.client = pahomqtt.NewClient(opts)
+	return result
+}
+
+// HandleCommands включает прием команд из топиков .../set, устройства ищутся в состоянии provider.
+// Вызывается до запуска сервиса.
+func (c *client) HandleCommands(provider DeviceProvider) {
+	c.provider = provider
+}
+
+func (c *client) Run(ctx context.Context, ready func()) error {
+	logger := log.Ctx(ctx).With().Str("broker", c.config.Broker).Logger()
+	ctx, c.cancelFunc = context.WithCancel(logger.WithContext(ctx))
+	defer c.cancelFunc()
+	c.ctx = ctx
+	token := c.client.Connect()
+	// при недоступном брокере клиент продолжает подключаться в фоне, старт сервиса не блокируем
+	if token.WaitTimeout(c.config.Timeout) {
+		if err := token.Error(); err != nil {
+			logger.Error().Err(err).Msg("Failed connect to broker")
+			return err
+		}
+		logger.Info().Msg("Connected to broker")
+	} else {
+		logger.Warn().Msg("Broker is not available yet, keep connecting in background")
+	}
+	ready()
+	for {
+		select {
+		case <-ctx.Done():
+			c.client.Disconnect(disconnectQuiesce)
+			return nil
+		case msg := <-c.queue:
+			c.send(msg)
+		}
+	}
+}
+
+func (c *client) Shutdown(ctx context.Context) error {
+	if c.cancelFunc != nil {
+		c.cancelFunc()
+	}
+	return nil
+}
+
+func (c *client) NotifyDevicesChanged(ctx context.Context, userID string, changes []notifier.DeviceChange) error {
+	var errs []error
+	for _, change := range changes {
+		device := change.Device
+		base := c.deviceTopic(userID, device)
+		blob, err := json.Marshal(deviceState{
+			Name:             device.Name,
+			Model:            device.Model,
+			House:            device.House.Name,
+			Enabled:          device.Enabled,
+			Connected:        device.Connected,
+			Setpoint:         device.Tempometer.SetDegreesFloor,
+			FloorTemperature: device.Tempometer.DegreesFloor,
+			AirTemperature:   device.Tempometer.DegreesAir,
+			UpdatedAt:        device.UpdatedAt,
+		})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if c.config.HADiscovery && change.Changes == device_provider.ChangedAll {
+			errs = append(errs, c.publishDiscovery(ctx, userID, device))
+		}
+		errs = append(errs, c.publish(ctx, base+topicState, blob))
+		if change.Changes.Has(device_provider.ChangedEnabled) {
+			errs = append(errs, c.publish(ctx, base+topicPower, []byte(onOff(device.Enabled))))
+			errs = append(errs, c.publish(ctx, base+topicMode, []byte(mode(device.Enabled))))
+		}
+		if change.Changes.Has(device_provider.ChangedConnected) {
+			errs = append(errs, c.publish(ctx, base+topicConnected, []byte(availability(device.Connected))))
+		}
+		if change.Changes.Has(device_provider.ChangedSetDegreesFloor) {
+			errs = append(errs, c.publish(ctx, base+topicSetpoint, []byte(device.Tempometer.SetDegreesFloor.String())))
+		}
+		if change.Changes.Has(device_provider.ChangedDegreesFloor) {
+			errs = append(errs, c.publish(ctx, base+topicFloorTemperature, []byte(device.Tempometer.DegreesFloor.String())))
+		}
+		if change.Changes.Has(device_provider.ChangedDegreesAir) {
+			errs = append(errs, c.publish(ctx, base+topicAirTemperature, []byte(device.Tempometer.DegreesAir.String())))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *client) NotifyDevicesListChanged(ctx context.Context, userID string) error {
+	// состояние каждого устройства публикуется в собственные retained топики, отдельного списка нет,
+	// но из Home Assistant нужно убрать пропавшие устройства
+	if !c.config.HADiscovery || c.provider == nil {
+		return nil
+	}
+	return c.removeStaleDiscovery(ctx, userID, c.provider.Devices(userID))
+}
+
+func (c *client) deviceTopic(userID string, device *device_provider.Device) string {
+	return c.topic(userID, deviceKey{houseID: device.House.ID, deviceID: device.ID})
+}
+
+func (c *client) topic(userID string, key deviceKey) string {
+	return strings.Join([]string{c.config.TopicPrefix, userID, strconv.Itoa(key.houseID), strconv.Itoa(key.deviceID), ""}, "/")
+}
+
+func (c *client) publish(ctx context.Context, topic string, payload []byte) error {
+	return c.publishRetain(ctx, topic, c.config.Retain, payload)
+}
+
+// publishRetain ставит сообщение в очередь, ошибка возвращается только если очередь переполнена
+func (c *client) publishRetain(ctx context.Context, topic string, retain bool, payload []byte) error {
+	select {
+	case c.queue <- message{ctx: ctx, topic: topic, retain: retain, payload: payload}:
+		return nil
+	default:
+		err := errors.New("publish queue is full")
+		log.Ctx(ctx).Error().Err(err).Str("topic", topic).Msg("Failed publish")
+		return err
+	}
+}
+
+func (c *client) send(msg message) {
+	logger := log.Ctx(msg.ctx).With().Str("topic", msg.topic).Logger()
+	token := c.client.Publish(msg.topic, c.config.QOS, msg.retain, msg.payload)
+	if !token.WaitTimeout(c.config.Timeout) {
+		logger.Error().Err(errors.New("publish timeout")).Msg("Failed publish")
+		return
+	}
+	if err := token.Error(); err != nil {
+		logger.Error().Err(err).Msg("Failed publish")
+		return
+	}
+	logger.Trace().Bytes("payload", msg.payload).Msg("Published")
+}
+
+func onOff(v bool) string {
+	if v {
+		return payloadOn
+	}
+	return payloadOff
+}
+
+func mode(v bool) string {
+	if v {
+		return payloadHeat
+	}
+	return payloadOff
+}
+
+func availability(v bool) string {
+	if v {
+		return payloadOnline
+	}
+	return payloadOffline
+}
@@ -0,0 +1,31 @@
+package mqtt
+
+import (
+	"errors"
+	"time"
+)
+
+type Config struct {
+	// Broker адрес брокера, например tcp://localhost:1883
+	Broker   string        `env:"MQTT_BROKER"`
+	ClientID string        `env:"MQTT_CLIENT_ID,default=sstcloud-alice-gateway"`
+	Username string        `env:"MQTT_USERNAME"`
+	Password string        `env:"MQTT_PASSWORD"`
+	Timeout  time.Duration `env:"MQTT_TIMEOUT,default=5s"`
+	// TopicPrefix корень топиков: <prefix>/<user>/<house>/<device>/...
+	TopicPrefix string `env:"MQTT_TOPIC_PREFIX,default=sstcloud"`
+	QOS         byte   `env:"MQTT_QOS,default=1"`
+	Retain      bool   `env:"MQTT_RETAIN,default=true"`
+	// QueueSize сколько сообщений ждут отправки, пока брокер недоступен, остальные отбрасываются
+	QueueSize int `env:"MQTT_QUEUE_SIZE,default=1024"`
+	// HADiscovery публиковать конфигурации Home Assistant MQTT discovery и принимать команды из топиков .../set
+	HADiscovery       bool   `env:"MQTT_HA_DISCOVERY"`
+	HADiscoveryPrefix string `env:"MQTT_HA_DISCOVERY_PREFIX,default=homeassistant"`
+}
+
+func (c Config) Validate() error {
+	if c.Broker == "" {
+		return errors.New("MQTT_BROKER is required")
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	pahomqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"sstcloud-alice-gateway/internal/device_provider"
+	"sstcloud-alice-gateway/internal/i18n"
+	"sstcloud-alice-gateway/internal/mappers"
+	storageModels "sstcloud-alice-gateway/internal/models/storage"
+)
+
+const (
+	commandSuffix = "set"
+	manufacturer  = "SST"
+)
+
+type deviceKey struct {
+	houseID  int
+	deviceID int
+}
+
+type haDevice struct {
+	Identifiers   []string `json:"identifiers"`
+	Name          string   `json:"name"`
+	Model         string   `json:"model"`
+	Manufacturer  string   `json:"manufacturer"`
+	SuggestedArea string   `json:"suggested_area,omitempty"`
+}
+
+type haClimate struct {
+	Name                    string   `json:"name"`
+	UniqueID                string   `json:"unique_id"`
+	Modes                   []string `json:"modes"`
+	ModeStateTopic          string   `json:"mode_state_topic"`
+	ModeCommandTopic        string   `json:"mode_command_topic"`
+	PowerCommandTopic       string   `json:"power_command_topic"`
+	TemperatureStateTopic   string   `json:"temperature_state_topic"`
+	TemperatureCommandTopic string   `json:"temperature_command_topic"`
+	CurrentTemperatureTopic string   `json:"current_temperature_topic"`
+	MinTemp                 float32  `json:"min_temp"`
+	MaxTemp                 float32  `json:"max_temp"`
+	TempStep                float32  `json:"temp_step"`
+	TemperatureUnit         string   `json:"temperature_unit"`
+	AvailabilityTopic       string   `json:"availability_topic"`
+	PayloadAvailable        string   `json:"payload_available"`
+	PayloadNotAvailable     string   `json:"payload_not_available"`
+	Device                  haDevice `json:"device"`
+}
+
+type haSensor struct {
+	Name                string   `json:"name"`
+	UniqueID            string   `json:"unique_id"`
+	DeviceClass         string   `json:"device_class"`
+	StateClass          string   `json:"state_class"`
+	StateTopic          string   `json:"state_topic"`
+	UnitOfMeasurement   string   `json:"unit_of_measurement"`
+	AvailabilityTopic   string   `json:"availability_topic"`
+	PayloadAvailable    string   `json:"payload_available"`
+	PayloadNotAvailable string   `json:"payload_not_available"`
+	Device              haDevice `json:"device"`
+}
+
+func (c *client) objectID(userID string, key deviceKey) string {
+	return fmt.Sprintf("sstcloud_%s_%d_%d", strings.ReplaceAll(userID, "-", ""), key.houseID, key.deviceID)
+}
+
+func (c *client) discoveryTopics(userID string, key deviceKey) []string {
+	objectID := c.objectID(userID, key)
+	return []string{
+		strings.Join([]string{c.config.HADiscoveryPrefix, "climate", objectID, "config"}, "/"),
+		strings.Join([]string{c.config.HADiscoveryPrefix, "sensor", objectID + "_" + mappers.AdditionalSensorAir, "config"}, "/"),
+		strings.Join([]string{c.config.HADiscoveryPrefix, "sensor", objectID + "_" + mappers.AdditionalSensorFloor, "config"}, "/"),
+	}
+}
+
+func (c *client) publishDiscovery(ctx context.Context, userID string, device *device_provider.Device) error {
+	key := deviceKey{houseID: device.House.ID, deviceID: device.ID}
+	base := c.topic(userID, key)
+	objectID := c.objectID(userID, key)
+	messages := i18n.For(device.House.Language)
+	haDev := haDevice{
+		Identifiers:   []string{objectID},
+		Name:          device.Name,
+		Model:         device.Model,
+		Manufacturer:  manufacturer,
+		SuggestedArea: device.House.Name,
+	}
+	climate := haClimate{
+		Name:                    device.Name,
+		UniqueID:                objectID,
+		Modes:                   []string{payloadOff, payloadHeat},
+		ModeStateTopic:          base + topicMode,
+		ModeCommandTopic:        base + topicMode + "/" + commandSuffix,
+		PowerCommandTopic:       base + topicPower + "/" + commandSuffix,
+		TemperatureStateTopic:   base + topicSetpoint,
+		TemperatureCommandTopic: base + topicSetpoint + "/" + commandSuffix,
+		CurrentTemperatureTopic: base + topicFloorTemperature,
+		MinTemp:                 float32(device.Limits.Min.Float()),
+		MaxTemp:                 float32(device.Limits.Max.Float()),
+		TempStep:                float32(device.Limits.Precision.Float()),
+		TemperatureUnit:         "C",
+		AvailabilityTopic:       base + topicConnected,
+		PayloadAvailable:        payloadOnline,
+		PayloadNotAvailable:     payloadOffline,
+		Device:                  haDev,
+	}
+	sensors := []haSensor{
+		c.sensor(objectID+"_"+mappers.AdditionalSensorAir, device.Name+" "+messages.SensorAir, base, topicAirTemperature, haDev),
+		c.sensor(objectID+"_"+mappers.AdditionalSensorFloor, device.Name+" "+messages.SensorFloor, base, topicFloorTemperature, haDev),
+	}
+	topics := c.discoveryTopics(userID, key)
+	payloads := []interface{}{climate, sensors[0], sensors[1]}
+	var errs []error
+	for i, payload := range payloads {
+		blob, err := json.Marshal(payload)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		errs = append(errs, c.publishRetain(ctx, topics[i], true, blob))
+	}
+	c.discoveredM.Lock()
+	if _, exists := c.discovered[userID]; !exists {
+		c.discovered[userID] = map[deviceKey]struct{}{}
+	}
+	c.discovered[userID][key] = struct{}{}
+	c.discoveredM.Unlock()
+	return errors.Join(errs...)
+}
+
+func (c *client) sensor(uniqueID, name, base, topic string, device haDevice) haSensor {
+	return haSensor{
+		Name:                name,
+		UniqueID:            uniqueID,
+		DeviceClass:         "temperature",
+		StateClass:          "measurement",
+		StateTopic:          base + topic,
+		UnitOfMeasurement:   "°C",
+		AvailabilityTopic:   base + topicConnected,
+		PayloadAvailable:    payloadOnline,
+		PayloadNotAvailable: payloadOffline,
+		Device:              device,
+	}
+}
+
+// removeStaleDiscovery публикует пустые конфигурации для устройств, которых больше нет у пользователя.
+func (c *client) removeStaleDiscovery(ctx context.Context, userID string, devices []*device_provider.Device) error {
+	current := make(map[deviceKey]struct{}, len(devices))
+	for _, device := range devices {
+		current[deviceKey{houseID: device.House.ID, deviceID: device.ID}] = struct{}{}
+	}
+	var stale []deviceKey
+	c.discoveredM.Lock()
+	for key := range c.discovered[userID] {
+		if _, exists := current[key]; exists {
+			continue
+		}
+		stale = append(stale, key)
+		delete(c.discovered[userID], key)
+	}
+	c.discoveredM.Unlock()
+	var errs []error
+	for _, key := range stale {
+		for _, topic := range c.discoveryTopics(userID, key) {
+			errs = append(errs, c.publishRetain(ctx, topic, true, nil))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// onConnect подписывается на команды заново при каждом подключении: сессия брокера может быть потеряна.
+func (c *client) onConnect(cl pahomqtt.Client) {
+	if !c.config.HADiscovery || c.provider == nil {
+		return
+	}
+	logger := log.Ctx(c.ctx)
+	filters := map[string]byte{}
+	for _, topic := range []string{topicSetpoint, topicPower, topicMode} {
+		filters[strings.Join([]string{c.config.TopicPrefix, "+", "+", "+", topic, commandSuffix}, "/")] = c.config.QOS
+	}
+	token := cl.SubscribeMultiple(filters, c.handleCommand)
+	if !token.WaitTimeout(c.config.Timeout) {
+		logger.Error().Msg("Subscribe timeout")
+		return
+	}
+	if err := token.Error(); err != nil {
+		logger.Error().Err(err).Msg("Failed subscribe to command topics")
+		return
+	}
+	logger.Info().Msg("Subscribed to command topics")
+}
+
+func (c *client) handleCommand(_ pahomqtt.Client, msg pahomqtt.Message) {
+	// MessageID при QoS 0 всегда 0, поэтому для журнала команд у каждой команды свой идентификатор
+	requestID := uuid.NewString()
+	logger := log.Ctx(c.ctx).With().Str("topic", msg.Topic()).Str("payload", string(msg.Payload())).Str("request_id", requestID).Logger()
+	ctx := device_provider.WithCommandSource(logger.WithContext(c.ctx), string(storageModels.CommandOriginMQTT), requestID)
+	parts := strings.Split(strings.TrimPrefix(msg.Topic(), c.config.TopicPrefix+"/"), "/")
+	if len(parts) != 5 || parts[4] != commandSuffix {
+		logger.Warn().Msg("Unexpected command topic")
+		return
+	}
+	userID := parts[0]
+	houseID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		logger.Warn().Err(err).Msg("Invalid house id")
+		return
+	}
+	deviceID, err := strconv.Atoi(parts[2])
+	if err != nil {
+		logger.Warn().Err(err).Msg("Invalid device id")
+		return
+	}
+	var device *device_provider.Device
+	for _, d := range c.provider.Devices(userID) {
+		if d.House.ID == houseID && d.ID == deviceID {
+			device = d
+			break
+		}
+	}
+	if device == nil {
+		logger.Warn().Msg("Device not found")
+		return
+	}
+	payload := strings.TrimSpace(string(msg.Payload()))
+	switch parts[3] {
+	case topicSetpoint:
+		value, parseErr := device_provider.ParseTemperature(payload)
+		if parseErr != nil {
+			logger.Warn().Err(parseErr).Msg("Invalid temperature")
+			return
+		}
+		temp, ok := device.Limits.Normalize(value)
+		if !ok {
+			logger.Warn().Stringer("value", temp).Msg("Temperature out of range")
+			return
+		}
+		err = device.SetTemperature(ctx, temp)
+	case topicPower, topicMode:
+		on, off := payloadOn, payloadOff
+		if parts[3] == topicMode {
+			on = payloadHeat
+		}
+		power, parseErr := parseSwitch(payload, on, off)
+		if parseErr != nil {
+			logger.Warn().Err(parseErr).Msg("Invalid power command")
+			return
+		}
+		err = device.PowerStatus(ctx, power)
+	}
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed execute command")
+		return
+	}
+	c.provider.Refresh(userID, houseID)
+}
+
+// parseSwitch принимает только значения включения и выключения: опечатка, пустое сообщение или мусор
+// в retained топике не должны выключать термостат
+func parseSwitch(payload, on, off string) (bool, error) {
+	switch {
+	case strings.EqualFold(payload, on):
+		return true, nil
+	case strings.EqualFold(payload, off):
+		return false, nil
+	}
+	return false, fmt.Errorf("expected %s or %s, got %q", on, off, payload)
+}
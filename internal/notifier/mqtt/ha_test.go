@@ -0,0 +1,187 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"sstcloud-alice-gateway/internal/device_provider"
+)
+
+type fakeMessage struct {
+	topic   string
+	payload string
+}
+
+func (m fakeMessage) Duplicate() bool   { return false }
+func (m fakeMessage) Qos() byte         { return 0 }
+func (m fakeMessage) Retained() bool    { return false }
+func (m fakeMessage) Topic() string     { return m.topic }
+func (m fakeMessage) MessageID() uint16 { return 0 }
+func (m fakeMessage) Payload() []byte   { return []byte(m.payload) }
+func (m fakeMessage) Ack()              {}
+
+// commandRecorder запоминает команды, дошедшие до провайдера
+type commandRecorder struct {
+	device_provider.DeviceProvider
+	power       *bool
+	temperature *device_provider.Temperature
+}
+
+func (r *commandRecorder) SetTemperature(_ context.Context, _ *device_provider.Device, temp device_provider.Temperature) error {
+	r.temperature = &temp
+	return nil
+}
+
+func (r *commandRecorder) PowerStatus(_ context.Context, _ *device_provider.Device, power bool) error {
+	r.power = &power
+	return nil
+}
+
+type fakeDevices struct {
+	devices   []*device_provider.Device
+	refreshed int
+}
+
+func (f *fakeDevices) Devices(string) []*device_provider.Device { return f.devices }
+
+func (f *fakeDevices) Refresh(string, int) bool {
+	f.refreshed++
+	return true
+}
+
+func newTestClient() (*client, *commandRecorder, *fakeDevices) {
+	recorder := &commandRecorder{}
+	devices := &fakeDevices{devices: []*device_provider.Device{{
+		House: &device_provider.House{ID: 1, Name: "Дача", DeviceProvider: recorder},
+		ID:    2,
+		Name:  "Кухня",
+		Limits: device_provider.Limits{
+			Min:       device_provider.Degrees(12),
+			Max:       device_provider.Degrees(45),
+			Precision: device_provider.Degrees(1),
+			Rounding:  device_provider.RoundNearest,
+		},
+	}}}
+	c := &client{
+		config: Config{
+			TopicPrefix:       "sstcloud",
+			HADiscovery:       true,
+			HADiscoveryPrefix: "homeassistant",
+		},
+		ctx:        context.Background(),
+		provider:   devices,
+		discovered: map[string]map[deviceKey]struct{}{},
+		queue:      make(chan message, 10),
+	}
+	return c, recorder, devices
+}
+
+func TestHandleCommand(t *testing.T) {
+	on, off := true, false
+	degrees := func(d int) *device_provider.Temperature {
+		t := device_provider.Degrees(d)
+		return &t
+	}
+	tests := []struct {
+		name        string
+		topic       string
+		payload     string
+		power       *bool
+		temperature *device_provider.Temperature
+	}{
+		{name: "power on", topic: "sstcloud/user/1/2/power/set", payload: "ON", power: &on},
+		{name: "power off", topic: "sstcloud/user/1/2/power/set", payload: "OFF", power: &off},
+		{name: "power case insensitive", topic: "sstcloud/user/1/2/power/set", payload: " off\n", power: &off},
+		{name: "power empty", topic: "sstcloud/user/1/2/power/set", payload: ""},
+		{name: "power typo", topic: "sstcloud/user/1/2/power/set", payload: "OM"},
+		{name: "power mode value", topic: "sstcloud/user/1/2/power/set", payload: "heat"},
+		{name: "mode heat", topic: "sstcloud/user/1/2/mode/set", payload: "heat", power: &on},
+		{name: "mode off", topic: "sstcloud/user/1/2/mode/set", payload: "off", power: &off},
+		{name: "mode power value", topic: "sstcloud/user/1/2/mode/set", payload: "ON"},
+		{name: "mode junk", topic: "sstcloud/user/1/2/mode/set", payload: `{"mode":"heat"}`},
+		{name: "setpoint", topic: "sstcloud/user/1/2/setpoint/set", payload: "25", temperature: degrees(25)},
+		{name: "setpoint rounded", topic: "sstcloud/user/1/2/setpoint/set", payload: "24.6", temperature: degrees(25)},
+		{name: "setpoint out of range", topic: "sstcloud/user/1/2/setpoint/set", payload: "60"},
+		{name: "setpoint not a number", topic: "sstcloud/user/1/2/setpoint/set", payload: "warm"},
+		{name: "unknown device", topic: "sstcloud/user/1/3/power/set", payload: "ON"},
+		{name: "invalid house", topic: "sstcloud/user/x/2/power/set", payload: "ON"},
+		{name: "state topic", topic: "sstcloud/user/1/2/power", payload: "ON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, recorder, devices := newTestClient()
+			c.handleCommand(nil, fakeMessage{topic: tt.topic, payload: tt.payload})
+			if (recorder.power == nil) != (tt.power == nil) || (tt.power != nil && *recorder.power != *tt.power) {
+				t.Fatalf("power %v, want %v", recorder.power, tt.power)
+			}
+			if (recorder.temperature == nil) != (tt.temperature == nil) || (tt.temperature != nil && *recorder.temperature != *tt.temperature) {
+				t.Fatalf("temperature %v, want %v", recorder.temperature, tt.temperature)
+			}
+			// после выполненной команды дом перечитывается, после отклоненной - нет
+			executed := tt.power != nil || tt.temperature != nil
+			if (devices.refreshed == 1) != executed {
+				t.Fatalf("refreshed %d times, executed %v", devices.refreshed, executed)
+			}
+		})
+	}
+}
+
+func TestDiscoveryTopics(t *testing.T) {
+	c, recorder, _ := newTestClient()
+	device := c.provider.Devices("user")[0]
+	if err := c.publishDiscovery(context.Background(), "user-1", device); err != nil {
+		t.Fatal(err)
+	}
+	const objectID = "sstcloud_user1_1_2"
+	wantTopics := []string{
+		"homeassistant/climate/" + objectID + "/config",
+		"homeassistant/sensor/" + objectID + "_air/config",
+		"homeassistant/sensor/" + objectID + "_floor/config",
+	}
+	var climate haClimate
+	for i, want := range wantTopics {
+		msg := <-c.queue
+		if msg.topic != want || !msg.retain {
+			t.Fatalf("message %d: topic %q retain %v, want %q", i, msg.topic, msg.retain, want)
+		}
+		if i == 0 {
+			if err := json.Unmarshal(msg.payload, &climate); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	const base = "sstcloud/user-1/1/2/"
+	for topic, want := range map[string]string{
+		climate.ModeStateTopic:          base + "mode",
+		climate.ModeCommandTopic:        base + "mode/set",
+		climate.PowerCommandTopic:       base + "power/set",
+		climate.TemperatureStateTopic:   base + "setpoint",
+		climate.TemperatureCommandTopic: base + "setpoint/set",
+		climate.CurrentTemperatureTopic: base + "floor_temperature",
+		climate.AvailabilityTopic:       base + "connected",
+	} {
+		if topic != want {
+			t.Fatalf("topic %q, want %q", topic, want)
+		}
+	}
+	if climate.MinTemp != 12 || climate.MaxTemp != 45 || climate.TempStep != 1 {
+		t.Fatalf("unexpected limits %+v", climate)
+	}
+	// команда из опубликованного топика доходит до устройства
+	c.handleCommand(nil, fakeMessage{topic: climate.ModeCommandTopic, payload: climate.Modes[1]})
+	if recorder.power == nil || !*recorder.power {
+		t.Fatalf("mode %q from discovery did not turn device on", climate.Modes[1])
+	}
+
+	// устройство пропало - конфигурации очищаются пустыми retained сообщениями
+	if err := c.removeStaleDiscovery(context.Background(), "user-1", nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range wantTopics {
+		msg := <-c.queue
+		if msg.topic != want || len(msg.payload) != 0 || !msg.retain {
+			t.Fatalf("unexpected cleanup message %+v", msg)
+		}
+	}
+}
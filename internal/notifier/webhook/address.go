@@ -0,0 +1,86 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var ErrForbiddenAddress = errors.New("webhook address is not allowed")
+
+// forbiddenNetworks диапазоны, которые не покрываются методами net.IP: "эта сеть" и CGNAT провайдера
+var forbiddenNetworks = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),
+	mustParseCIDR("100.64.0.0/10"),
+}
+
+func mustParseCIDR(s string) *net.IPNet {
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
+
+// forbiddenIP адреса внутренней сети и самого шлюза, иначе вебхуком можно обращаться к ним от имени шлюза
+func forbiddenIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	for _, network := range forbiddenNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateURL проверяет адрес вебхука при регистрации: http(s) и хост, который не указывает во внутреннюю сеть.
+// При отправке адрес проверяется еще раз в dialer, так как DNS может измениться.
+func ValidateURL(ctx context.Context, rawURL string, allowPrivate bool) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return errors.New("url must be absolute http(s) address")
+	}
+	if allowPrivate {
+		return nil
+	}
+	ips, err := net.DefaultResolver.LookupIP(ctx, "ip", parsed.Hostname())
+	if err != nil {
+		return fmt.Errorf("resolve %s: %w", parsed.Hostname(), err)
+	}
+	for _, ip := range ips {
+		if forbiddenIP(ip) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrForbiddenAddress, parsed.Hostname(), ip)
+		}
+	}
+	return nil
+}
+
+// newTransport транспорт, который проверяет уже разрешенный адрес перед подключением
+func newTransport(timeout time.Duration, allowPrivate bool) *http.Transport {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || forbiddenIP(ip) {
+				return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+			}
+			return nil
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// прокси подключался бы вместо адреса вебхука, и проверка в dialer потеряла бы смысл
+	transport.Proxy = nil
+	return transport
+}
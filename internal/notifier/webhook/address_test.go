@@ -0,0 +1,75 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestValidateURL(t *testing.T) {
+	tests := []struct {
+		url       string
+		forbidden bool
+		invalid   bool
+	}{
+		{url: "https://93.184.216.34/hook"},
+		{url: "http://127.0.0.1:8123/api/webhook/x", forbidden: true},
+		{url: "http://localhost/hook", forbidden: true},
+		{url: "http://169.254.169.254/latest/meta-data", forbidden: true},
+		{url: "http://10.0.0.5/hook", forbidden: true},
+		{url: "http://192.168.1.10/hook", forbidden: true},
+		{url: "http://[::1]/hook", forbidden: true},
+		{url: "http://[fe80::1]/hook", forbidden: true},
+		{url: "http://0.0.0.0/hook", forbidden: true},
+		{url: "http://0.1.2.3/hook", forbidden: true},
+		{url: "http://100.64.0.1/hook", forbidden: true},
+		{url: "http://100.127.255.254/hook", forbidden: true},
+		{url: "http://[::ffff:100.64.0.1]/hook", forbidden: true},
+		{url: "http://100.63.255.255/hook"},
+		{url: "http://100.128.0.1/hook"},
+		{url: "ftp://93.184.216.34/hook", invalid: true},
+		{url: "/hook", invalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := ValidateURL(context.Background(), tt.url, false)
+			switch {
+			case tt.forbidden:
+				if !errors.Is(err, ErrForbiddenAddress) {
+					t.Fatalf("expected ErrForbiddenAddress, got %v", err)
+				}
+				if err := ValidateURL(context.Background(), tt.url, true); err != nil {
+					t.Fatalf("expected private address to be allowed, got %v", err)
+				}
+			case tt.invalid:
+				if err == nil || errors.Is(err, ErrForbiddenAddress) {
+					t.Fatalf("expected invalid url error, got %v", err)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestTransportRejectsPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newTransport(time.Second, false)}
+	if _, err := client.Get(server.URL); !errors.Is(err, ErrForbiddenAddress) {
+		t.Fatalf("expected ErrForbiddenAddress, got %v", err)
+	}
+
+	client = &http.Client{Transport: newTransport(time.Second, true)}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
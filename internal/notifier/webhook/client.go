@@ -0,0 +1,251 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"sstcloud-alice-gateway/internal/device_provider"
+	"sstcloud-alice-gateway/internal/mappers"
+	storageModels "sstcloud-alice-gateway/internal/models/storage"
+	"sstcloud-alice-gateway/internal/notifier"
+)
+
+const (
+	EventDevicesChanged     = "devices.changed"
+	EventDevicesListChanged = "devices.list_changed"
+)
+
+const (
+	headerEvent     = "X-Webhook-Event"
+	headerSignature = "X-Webhook-Signature"
+	signaturePrefix = "sha256="
+	// responseLogLimit сколько байт ответа сохранять в журнал доставки
+	responseLogLimit = 1024
+)
+
+type Store interface {
+	Webhooks(ctx context.Context, userID string) ([]*storageModels.Webhook, error)
+	AddWebhookDelivery(ctx context.Context, delivery *storageModels.WebhookDelivery) error
+}
+
+type Event struct {
+	Event   string        `json:"event"`
+	UserID  string        `json:"user_id"`
+	TS      int64         `json:"ts"`
+	Devices []EventDevice `json:"devices,omitempty"`
+}
+
+type EventDevice struct {
+	ID               string                      `json:"id"`
+	HouseID          int                         `json:"house_id"`
+	DeviceID         int                         `json:"device_id"`
+	Name             string                      `json:"name"`
+	Model            string                      `json:"model"`
+	Changes          []string                    `json:"changes"`
+	Enabled          bool                        `json:"enabled"`
+	Connected        bool                        `json:"connected"`
+	Setpoint         device_provider.Temperature `json:"setpoint"`
+	FloorTemperature device_provider.Temperature `json:"floor_temperature"`
+	AirTemperature   device_provider.Temperature `json:"air_temperature"`
+	UpdatedAt        time.Time                   `json:"updated_at"`
+}
+
+var changeNames = []struct {
+	change device_provider.Changes
+	name   string
+}{
+	{change: device_provider.ChangedEnabled, name: "power"},
+	{change: device_provider.ChangedConnected, name: "connected"},
+	{change: device_provider.ChangedSetDegreesFloor, name: "setpoint"},
+	{change: device_provider.ChangedDegreesFloor, name: "floor_temperature"},
+	{change: device_provider.ChangedDegreesAir, name: "air_temperature"},
+}
+
+type client struct {
+	config     Config
+	store      Store
+	client     *http.Client
+	ctx        context.Context
+	cancelFunc context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+func New(config Config, store Store) *client {
+	return &client{
+		config: config,
+		store:  store,
+		client: &http.Client{
+			Timeout:   config.Timeout,
+			Transport: newTransport(config.Timeout, config.AllowPrivateAddresses),
+		},
+	}
+}
+
+func (c *client) Run(ctx context.Context, ready func()) error {
+	c.ctx, c.cancelFunc = context.WithCancel(ctx)
+	defer func() {
+		c.cancelFunc()
+		c.wg.Wait()
+	}()
+	ready()
+	<-c.ctx.Done()
+	return nil
+}
+
+func (c *client) Shutdown(ctx context.Context) error {
+	if c.cancelFunc != nil {
+		c.cancelFunc()
+	}
+	return nil
+}
+
+func (c *client) NotifyDevicesChanged(ctx context.Context, userID string, changes []notifier.DeviceChange) error {
+	event := Event{
+		Event:   EventDevicesChanged,
+		UserID:  userID,
+		TS:      time.Now().Unix(),
+		Devices: make([]EventDevice, 0, len(changes)),
+	}
+	for _, change := range changes {
+		device := change.Device
+		names := make([]string, 0, len(changeNames))
+		for _, n := range changeNames {
+			if change.Changes.Has(n.change) {
+				names = append(names, n.name)
+			}
+		}
+		event.Devices = append(event.Devices, EventDevice{
+			ID:               mappers.DeviceIDOf(device).String(),
+			HouseID:          device.House.ID,
+			DeviceID:         device.ID,
+			Name:             device.Name,
+			Model:            device.Model,
+			Changes:          names,
+			Enabled:          device.Enabled,
+			Connected:        device.Connected,
+			Setpoint:         device.Tempometer.SetDegreesFloor,
+			FloorTemperature: device.Tempometer.DegreesFloor,
+			AirTemperature:   device.Tempometer.DegreesAir,
+			UpdatedAt:        device.UpdatedAt,
+		})
+	}
+	return c.dispatch(ctx, event)
+}
+
+func (c *client) NotifyDevicesListChanged(ctx context.Context, userID string) error {
+	return c.dispatch(ctx, Event{
+		Event:  EventDevicesListChanged,
+		UserID: userID,
+		TS:     time.Now().Unix(),
+	})
+}
+
+// dispatch запускает доставку события во все вебхуки пользователя, повторы идут в фоне.
+func (c *client) dispatch(ctx context.Context, event Event) error {
+	logger := log.Ctx(ctx)
+	if c.ctx == nil || c.ctx.Err() != nil {
+		return nil
+	}
+	webhooks, err := c.store.Webhooks(ctx, event.UserID)
+	if err != nil {
+		return err
+	}
+	if len(webhooks) == 0 {
+		return nil
+	}
+	blob, err := json.Marshal(event)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed marshal webhook event")
+		return err
+	}
+	for _, webhook := range webhooks {
+		webhook := webhook
+		logger := log.Ctx(c.ctx).With().Str("webhook_id", webhook.ID).Str("event", event.Event).Logger()
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.deliver(logger.WithContext(c.ctx), webhook, event.Event, blob)
+		}()
+	}
+	return nil
+}
+
+func (c *client) deliver(ctx context.Context, webhook *storageModels.Webhook, event string, blob []byte) {
+	logger := log.Ctx(ctx)
+	delay := c.config.RetryMin
+	for attempt := 1; attempt <= c.config.MaxAttempts; attempt++ {
+		statusCode, err := c.post(ctx, webhook, event, blob)
+		delivery := storageModels.WebhookDelivery{
+			WebhookID:  webhook.ID,
+			Event:      event,
+			Payload:    string(blob),
+			Attempt:    attempt,
+			StatusCode: statusCode,
+			Time:       time.Now().UTC(),
+		}
+		if err != nil {
+			delivery.Error = err.Error()
+		}
+		if storeErr := c.store.AddWebhookDelivery(ctx, &delivery); storeErr != nil {
+			logger.Error().Err(storeErr).Msg("Failed save webhook delivery")
+		}
+		if err == nil {
+			return
+		}
+		if statusCode >= 400 && statusCode < 500 && statusCode != http.StatusTooManyRequests {
+			logger.Error().Err(err).Int("attempt", attempt).Msg("Webhook rejected event")
+			return
+		}
+		logger.Warn().Err(err).Int("attempt", attempt).Msg("Failed deliver webhook")
+		if attempt == c.config.MaxAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > c.config.RetryMax {
+			delay = c.config.RetryMax
+		}
+	}
+	logger.Error().Int("attempts", c.config.MaxAttempts).Msg("Webhook delivery failed, giving up")
+}
+
+func (c *client) post(ctx context.Context, webhook *storageModels.Webhook, event string, blob []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(blob))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(headerEvent, event)
+	req.Header.Set(headerSignature, signaturePrefix+Sign(webhook.Secret, blob))
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, responseLogLimit))
+		return resp.StatusCode, fmt.Errorf("unexpected status %s: %s", resp.Status, body)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign подпись тела запроса, получатель сверяет ее с заголовком X-Webhook-Signature.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,16 @@
+package webhook
+
+import (
+	"time"
+)
+
+type Config struct {
+	Timeout time.Duration `env:"WEBHOOK_TIMEOUT,default=5s"`
+	// MaxAttempts сколько раз пытаться доставить событие, прежде чем отказаться
+	MaxAttempts int `env:"WEBHOOK_MAX_ATTEMPTS,default=5"`
+	// RetryMin задержка перед первым повтором, дальше удваивается до RetryMax
+	RetryMin time.Duration `env:"WEBHOOK_RETRY_MIN,default=2s"`
+	RetryMax time.Duration `env:"WEBHOOK_RETRY_MAX,default=1m"`
+	// AllowPrivateAddresses разрешить вебхуки во внутреннюю сеть и на localhost, только для доверенных пользователей
+	AllowPrivateAddresses bool `env:"WEBHOOK_ALLOW_PRIVATE_ADDRESSES"`
+}
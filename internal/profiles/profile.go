@@ -0,0 +1,111 @@
+package profiles
+
+import (
+	"sync"
+	"time"
+
+	"sstcloud-alice-gateway/internal/device_provider"
+	"sstcloud-alice-gateway/internal/i18n"
+	"sstcloud-alice-gateway/internal/models/alice"
+)
+
+type Capability string
+
+const (
+	CapabilityOnOff       Capability = "on_off"
+	CapabilityTemperature Capability = "temperature"
+)
+
+const (
+	SensorAir   = "air"
+	SensorFloor = "floor"
+)
+
+// Sensor датчик температуры, публикуемый отдельным устройством с суффиксом ID
+type Sensor struct {
+	ID      string
+	Name    func(messages *i18n.Messages) string
+	Value   func(device *device_provider.Device) (device_provider.Temperature, time.Time)
+	Changes device_provider.Changes
+	// Primary датчик присоединяется к термостату в объединенном представлении
+	Primary bool
+}
+
+// Profile описывает, что публикуется в Алису для модели устройства
+type Profile struct {
+	Model        string
+	DeviceType   alice.DeviceType
+	Limits       device_provider.Limits
+	Capabilities []Capability
+	Sensors      []Sensor
+}
+
+func (p *Profile) Has(capability Capability) bool {
+	for _, c := range p.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Profile) Sensor(id string) (Sensor, bool) {
+	for _, s := range p.Sensors {
+		if s.ID == id {
+			return s, true
+		}
+	}
+	return Sensor{}, false
+}
+
+// Primary датчик, который присоединяется к основному устройству в объединенном представлении
+func (p *Profile) Primary() (Sensor, bool) {
+	for _, s := range p.Sensors {
+		if s.Primary {
+			return s, true
+		}
+	}
+	return Sensor{}, false
+}
+
+var (
+	registry  = map[string]*Profile{}
+	registryM sync.RWMutex
+)
+
+// Register добавляет профиль модели, повторная регистрация заменяет профиль
+func Register(profile *Profile) {
+	registryM.Lock()
+	defer registryM.Unlock()
+	registry[profile.Model] = profile
+}
+
+func Lookup(model string) (*Profile, bool) {
+	registryM.RLock()
+	defer registryM.RUnlock()
+	profile, exists := registry[model]
+	return profile, exists
+}
+
+// HasSensor есть ли датчик с таким идентификатором хотя бы в одном профиле
+func HasSensor(id string) bool {
+	if _, exists := Thermostat.Sensor(id); exists {
+		return true
+	}
+	registryM.RLock()
+	defer registryM.RUnlock()
+	for _, profile := range registry {
+		if _, exists := profile.Sensor(id); exists {
+			return true
+		}
+	}
+	return false
+}
+
+// For профиль модели, для незарегистрированной модели используется Thermostat
+func For(model string) *Profile {
+	if profile, exists := Lookup(model); exists {
+		return profile
+	}
+	return Thermostat
+}
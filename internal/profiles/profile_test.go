@@ -0,0 +1,106 @@
+package profiles
+
+import (
+	"testing"
+
+	"sstcloud-alice-gateway/internal/device_provider"
+	"sstcloud-alice-gateway/internal/i18n"
+	"sstcloud-alice-gateway/internal/models/alice"
+	"sstcloud-alice-gateway/pkg/sst"
+)
+
+func TestRegisteredModels(t *testing.T) {
+	for _, model := range []sst.DeviceType{sst.MCS300, sst.MCS350} {
+		t.Run(model.String(), func(t *testing.T) {
+			profile, exists := Lookup(model.String())
+			if !exists {
+				t.Fatal("profile is not registered")
+			}
+			if profile.Model != model.String() || profile.DeviceType != alice.DeviceTypeThermostat {
+				t.Fatalf("unexpected profile %+v", profile)
+			}
+			if !profile.Has(CapabilityOnOff) || !profile.Has(CapabilityTemperature) {
+				t.Fatalf("unexpected capabilities %v", profile.Capabilities)
+			}
+			if profile.Limits.Min != device_provider.Degrees(12) || profile.Limits.Max != device_provider.Degrees(45) {
+				t.Fatalf("unexpected limits %+v", profile.Limits)
+			}
+			for _, id := range []string{SensorAir, SensorFloor} {
+				if _, exists := profile.Sensor(id); !exists {
+					t.Fatalf("sensor %q is missing", id)
+				}
+			}
+			if primary, exists := profile.Primary(); !exists || primary.ID != SensorFloor {
+				t.Fatalf("unexpected primary sensor %+v", primary)
+			}
+		})
+	}
+}
+
+func TestForUnknownModel(t *testing.T) {
+	if _, exists := Lookup(sst.NeptunProWWiFi.String()); exists {
+		t.Fatal("unsupported model must not be registered")
+	}
+	if For(sst.NeptunProWWiFi.String()) != Thermostat {
+		t.Fatal("unknown model must fall back to thermostat profile")
+	}
+}
+
+func TestRegisterReplaces(t *testing.T) {
+	const model = "test model"
+	Register(&Profile{Model: model, DeviceType: alice.DeviceTypeSensor})
+	Register(&Profile{Model: model, DeviceType: alice.DeviceTypeThermostat, Sensors: []Sensor{{ID: "water"}}})
+	profile, exists := Lookup(model)
+	if !exists || profile.DeviceType != alice.DeviceTypeThermostat {
+		t.Fatalf("unexpected profile %+v", profile)
+	}
+	if !HasSensor("water") {
+		t.Fatal("sensor of registered profile is not known")
+	}
+}
+
+func TestHasSensor(t *testing.T) {
+	for _, tt := range []struct {
+		id   string
+		want bool
+	}{
+		{id: SensorAir, want: true},
+		{id: SensorFloor, want: true},
+		{id: ""},
+		{id: "humidity"},
+		{id: "Air"},
+	} {
+		if got := HasSensor(tt.id); got != tt.want {
+			t.Fatalf("HasSensor(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestSensorValues(t *testing.T) {
+	device := &device_provider.Device{
+		Tempometer: device_provider.Tempometer{
+			DegreesAir:   device_provider.Degrees(21),
+			DegreesFloor: device_provider.Degrees(24),
+		},
+	}
+	messages := i18n.For(i18n.DefaultLanguage)
+	for _, tt := range []struct {
+		id      string
+		want    device_provider.Temperature
+		changes device_provider.Changes
+	}{
+		{id: SensorAir, want: device_provider.Degrees(21), changes: device_provider.ChangedDegreesAir},
+		{id: SensorFloor, want: device_provider.Degrees(24), changes: device_provider.ChangedDegreesFloor},
+	} {
+		sensor, _ := Thermostat.Sensor(tt.id)
+		if value, _ := sensor.Value(device); value != tt.want {
+			t.Fatalf("%s: value %v, want %v", tt.id, value, tt.want)
+		}
+		if sensor.Changes != tt.changes {
+			t.Fatalf("%s: changes %v, want %v", tt.id, sensor.Changes, tt.changes)
+		}
+		if sensor.Name(messages) == "" {
+			t.Fatalf("%s: empty name", tt.id)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package profiles
+
+import (
+	"time"
+
+	"sstcloud-alice-gateway/internal/device_provider"
+	"sstcloud-alice-gateway/internal/i18n"
+	"sstcloud-alice-gateway/internal/models/alice"
+	"sstcloud-alice-gateway/pkg/sst"
+)
+
+var (
+	airSensor = Sensor{
+		ID:   SensorAir,
+		Name: func(messages *i18n.Messages) string { return messages.SensorAir },
+		Value: func(device *device_provider.Device) (device_provider.Temperature, time.Time) {
+			return device.Tempometer.DegreesAir, device.Tempometer.ChangedAtDegreesAir
+		},
+		Changes: device_provider.ChangedDegreesAir,
+	}
+	floorSensor = Sensor{
+		ID:   SensorFloor,
+		Name: func(messages *i18n.Messages) string { return messages.SensorFloor },
+		Value: func(device *device_provider.Device) (device_provider.Temperature, time.Time) {
+			return device.Tempometer.DegreesFloor, device.Tempometer.ChangedAtDegreesFloor
+		},
+		Changes: device_provider.ChangedDegreesFloor,
+		Primary: true,
+	}
+)
+
+// Thermostat термостат теплого пола с датчиками воздуха и пола
+var Thermostat = &Profile{
+	DeviceType: alice.DeviceTypeThermostat,
+	Limits: device_provider.Limits{
+		Min:       device_provider.Degrees(12),
+		Max:       device_provider.Degrees(45),
+		Precision: device_provider.Degrees(1),
+		Rounding:  device_provider.RoundNearest,
+	},
+	Capabilities: []Capability{CapabilityOnOff, CapabilityTemperature},
+	Sensors:      []Sensor{airSensor, floorSensor},
+}
+
+func init() {
+	for _, model := range []sst.DeviceType{sst.MCS300, sst.MCS350} {
+		profile := *Thermostat
+		profile.Model = model.String()
+		Register(&profile)
+	}
+}
@@ -5,5 +5,29 @@ import (
 )
 
 type Config struct {
+	// RequestPeriod максимальный интервал опроса, до которого растет период в отсутствие изменений
 	RequestPeriod time.Duration `env:"REQUEST_PERIOD,default=5m"`
+	// MinRequestPeriod интервал опроса сразу после действий пользователя или обнаруженных изменений
+	MinRequestPeriod time.Duration `env:"MIN_REQUEST_PERIOD,default=15s"`
+	// RequestPeriodBackoff множитель, с которым растет интервал опроса без изменений
+	RequestPeriodBackoff float64 `env:"REQUEST_PERIOD_BACKOFF,default=2"`
+	// MeasurementsPeriod как часто сохранять показания в историю, 0 - не сохранять
+	MeasurementsPeriod time.Duration `env:"MEASUREMENTS_PERIOD,default=5m"`
+	// MeasurementsRetention сколько хранить историю показаний
+	MeasurementsRetention time.Duration `env:"MEASUREMENTS_RETENTION,default=2160h"`
+	// WebhookDeliveriesRetention сколько хранить журнал доставки вебхуков, 0 - не удалять
+	WebhookDeliveriesRetention time.Duration `env:"WEBHOOK_DELIVERIES_RETENTION,default=720h"`
+	// LinksPeriod как часто перечитывать привязки, чтобы подхватить новые и измененные, 0 - не перечитывать
+	LinksPeriod time.Duration `env:"LINKS_PERIOD,default=1m"`
+}
+
+func (c Config) nextPeriod(current time.Duration, changed bool) time.Duration {
+	if changed || current < c.MinRequestPeriod {
+		return c.MinRequestPeriod
+	}
+	next := time.Duration(float64(current) * c.RequestPeriodBackoff)
+	if next > c.RequestPeriod {
+		return c.RequestPeriod
+	}
+	return next
 }
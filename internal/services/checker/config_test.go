@@ -0,0 +1,56 @@
+package checker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextPeriod(t *testing.T) {
+	config := Config{
+		RequestPeriod:        5 * time.Minute,
+		MinRequestPeriod:     15 * time.Second,
+		RequestPeriodBackoff: 2,
+	}
+	tests := []struct {
+		name    string
+		current time.Duration
+		changed bool
+		want    time.Duration
+	}{
+		{name: "grows without changes", current: 15 * time.Second, want: 30 * time.Second},
+		{name: "keeps growing", current: 2 * time.Minute, want: 4 * time.Minute},
+		{name: "capped by max", current: 4 * time.Minute, want: 5 * time.Minute},
+		{name: "stays at max", current: 5 * time.Minute, want: 5 * time.Minute},
+		{name: "change resets from max", current: 5 * time.Minute, changed: true, want: 15 * time.Second},
+		{name: "change resets from middle", current: time.Minute, changed: true, want: 15 * time.Second},
+		{name: "after refresh", current: 0, want: 15 * time.Second},
+		{name: "below min", current: time.Second, want: 15 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := config.nextPeriod(tt.current, tt.changed); got != tt.want {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextPeriodSequence(t *testing.T) {
+	config := Config{
+		RequestPeriod:        5 * time.Minute,
+		MinRequestPeriod:     15 * time.Second,
+		RequestPeriodBackoff: 2,
+	}
+	// 15s -> 30s -> 1m -> 2m -> 4m -> 5m
+	want := []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}
+	period := config.MinRequestPeriod
+	for i, expected := range want {
+		period = config.nextPeriod(period, false)
+		if period != expected {
+			t.Fatalf("step %d: got %v, want %v", i, period, expected)
+		}
+	}
+	if period = config.nextPeriod(period, true); period != config.MinRequestPeriod {
+		t.Fatalf("change must reset period, got %v", period)
+	}
+}
@@ -8,26 +8,39 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"sstcloud-alice-gateway/internal/device_provider"
+	storageModels "sstcloud-alice-gateway/internal/models/storage"
 	"sstcloud-alice-gateway/internal/notifier"
+	"sstcloud-alice-gateway/internal/storage"
 )
 
 type houseWorker struct {
 	config           Config
 	provider         device_provider.DeviceProvider
 	notifier         notifier.Notifier
+	storage          storage.Storage
 	cancelFunc       context.CancelFunc
 	state            []*device_provider.Device
 	stateM           sync.Mutex
 	house            *device_provider.House
 	notifyCancelFunc context.CancelFunc
+	refreshCh        chan struct{}
+	// announce дом появился у уже опрошенной связки, Алису нужно известить о новых устройствах
+	announce bool
+	polled   bool
+	// recordedAt время последней записи показаний в историю
+	recordedAt time.Time
 }
 
-func newHouseWorker(config Config, provider device_provider.DeviceProvider, house *device_provider.House, notifier notifier.Notifier) *houseWorker {
+func newHouseWorker(config Config, provider device_provider.DeviceProvider, house *device_provider.House, notifier notifier.Notifier, storage storage.Storage, announce bool) *houseWorker {
 	return &houseWorker{
 		config:   config,
 		provider: provider,
 		notifier: notifier,
+		storage:  storage,
 		house:    house,
+		announce: announce,
+		// буфер в один элемент: повторные запросы до начала опроса схлопываются
+		refreshCh: make(chan struct{}, 1),
 	}
 }
 
@@ -37,14 +50,27 @@ func (w *houseWorker) run(ctx context.Context) {
 
 	r, err := w.provider.Devices(ctx, w.house)
 	w.updateDevices(ctx, r, err)
+	period := w.config.MinRequestPeriod
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-time.After(w.config.RequestPeriod):
-			r, err := w.provider.Devices(ctx, w.house)
-			w.updateDevices(ctx, r, err)
+		case <-w.refreshCh:
+			if invalidator, ok := w.provider.(device_provider.Invalidator); ok {
+				invalidator.Invalidate(w.house)
+			}
+			period = 0
+		case <-time.After(period):
 		}
+		r, err := w.provider.Devices(ctx, w.house)
+		period = w.config.nextPeriod(period, w.updateDevices(ctx, r, err))
+	}
+}
+
+func (w *houseWorker) refresh() {
+	select {
+	case w.refreshCh <- struct{}{}:
+	default:
 	}
 }
 
@@ -64,53 +90,110 @@ func (w *houseWorker) getState() []*device_provider.Device {
 	return result
 }
 
-func (w *houseWorker) updateDevices(ctx context.Context, devices []*device_provider.Device, err error) {
+func (w *houseWorker) updateDevices(ctx context.Context, devices []*device_provider.Device, err error) bool {
 	if err != nil {
-		return
+		return false
 	}
 	savedDeviceMap := map[int]*device_provider.Device{}
 	for _, device := range w.getState() {
 		savedDeviceMap[device.ID] = device
 	}
+	listChanged := len(savedDeviceMap) != len(devices)
+	notify := make([]notifier.DeviceChange, 0, len(devices))
+	changed := false
 	for _, device := range devices {
 		savedDevice, exists := savedDeviceMap[device.ID]
+		if !exists {
+			listChanged = true
+		}
+		changes := device.Changes(savedDevice)
+		if changes != device_provider.ChangedNone {
+			notify = append(notify, notifier.DeviceChange{Device: device, Changes: changes})
+		}
+		// показания датчиков меняются постоянно, поэтому на частоту опроса влияют только настройки и связь
+		if changes.Has(device_provider.ChangedEnabled | device_provider.ChangedConnected | device_provider.ChangedSetDegreesFloor) {
+			changed = true
+		}
 		if !exists {
 			continue
 		}
 
-		if savedDevice.Tempometer.DegreesFloor == device.Tempometer.DegreesFloor {
+		if !changes.Has(device_provider.ChangedDegreesFloor) {
 			device.Tempometer.ChangedAtDegreesFloor = savedDevice.Tempometer.ChangedAtDegreesFloor
 		}
-		if savedDevice.Tempometer.DegreesAir == device.Tempometer.DegreesAir {
+		if !changes.Has(device_provider.ChangedDegreesAir) {
 			device.Tempometer.ChangedAtDegreesAir = savedDevice.Tempometer.ChangedAtDegreesAir
 		}
-		if savedDevice.Tempometer.SetDegreesFloor == device.Tempometer.SetDegreesFloor {
+		if !changes.Has(device_provider.ChangedSetDegreesFloor) {
 			device.Tempometer.ChangedAtSetDegreesFloor = savedDevice.Tempometer.ChangedAtSetDegreesFloor
 		}
 	}
 	w.stateM.Lock()
 	w.state = devices
 	w.stateM.Unlock()
-	w.notify(ctx, devices)
+	if (w.polled && listChanged) || (!w.polled && w.announce) {
+		w.notifyListChanged(ctx)
+	}
+	w.polled = true
+	w.notify(ctx, notify)
+	w.record(ctx, devices)
+	return changed || listChanged
+}
+
+func (w *houseWorker) record(ctx context.Context, devices []*device_provider.Device) {
+	if w.config.MeasurementsPeriod <= 0 || time.Since(w.recordedAt) < w.config.MeasurementsPeriod {
+		return
+	}
+	now := time.Now().UTC()
+	measurements := make([]*storageModels.Measurement, 0, len(devices))
+	for _, device := range devices {
+		if !device.Connected {
+			continue
+		}
+		measurements = append(measurements, &storageModels.Measurement{
+			UserID:          w.house.UserID,
+			DeviceID:        device.IDStr,
+			Time:            now,
+			DegreesAir:      device.Tempometer.DegreesAir.Float(),
+			DegreesFloor:    device.Tempometer.DegreesFloor.Float(),
+			SetDegreesFloor: device.Tempometer.SetDegreesFloor.Float(),
+			Enabled:         device.Enabled,
+		})
+	}
+	if err := w.storage.AddMeasurements(ctx, measurements); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Failed record measurements")
+		return
+	}
+	w.recordedAt = now
 }
 
 func (w *houseWorker) markAllOffline(ctx context.Context) {
 	states := w.getState()
-	notify := make([]*device_provider.Device, 0, len(states))
+	notify := make([]notifier.DeviceChange, 0, len(states))
 	for _, state := range states {
 		if !state.Connected {
 			continue
 		}
 		state.Connected = false
-		notify = append(notify, state)
+		notify = append(notify, notifier.DeviceChange{Device: state, Changes: device_provider.ChangedConnected})
 	}
 	w.notify(ctx, notify)
 }
 
-func (w *houseWorker) notify(ctx context.Context, devices []*device_provider.Device) {
+func (w *houseWorker) notify(ctx context.Context, changes []notifier.DeviceChange) {
 	logger := log.Ctx(ctx)
-	if err := w.notifier.NotifyDevicesChanged(ctx, w.house, devices); err != nil {
+	if len(changes) == 0 {
+		return
+	}
+	if err := w.notifier.NotifyDevicesChanged(ctx, w.house.UserID, changes); err != nil {
 		logger.Error().Err(err).Msg("Failed notify")
 	}
 	return
 }
+
+func (w *houseWorker) notifyListChanged(ctx context.Context) {
+	logger := log.Ctx(ctx)
+	if err := w.notifier.NotifyDevicesListChanged(ctx, w.house.UserID); err != nil {
+		logger.Error().Err(err).Msg("Failed notify devices list changed")
+	}
+}
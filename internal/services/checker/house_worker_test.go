@@ -0,0 +1,93 @@
+package checker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"sstcloud-alice-gateway/internal/device_provider"
+	"sstcloud-alice-gateway/internal/notifier"
+)
+
+type countingProvider struct {
+	device_provider.DeviceProvider
+	mu          sync.Mutex
+	polls       int
+	invalidated int
+	polled      chan struct{}
+}
+
+func (p *countingProvider) Devices(context.Context, *device_provider.House) ([]*device_provider.Device, error) {
+	p.mu.Lock()
+	p.polls++
+	p.mu.Unlock()
+	p.polled <- struct{}{}
+	return nil, nil
+}
+
+func (p *countingProvider) Invalidate(*device_provider.House) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.invalidated++
+}
+
+type nopNotifier struct{}
+
+func (nopNotifier) NotifyDevicesChanged(context.Context, string, []notifier.DeviceChange) error {
+	return nil
+}
+
+func (nopNotifier) NotifyDevicesListChanged(context.Context, string) error { return nil }
+
+func TestHouseWorkerRefresh(t *testing.T) {
+	provider := &countingProvider{polled: make(chan struct{}, 10)}
+	// период больше времени теста: опрос возможен только по refresh
+	config := Config{RequestPeriod: time.Hour, MinRequestPeriod: time.Hour, RequestPeriodBackoff: 2}
+	worker := newHouseWorker(config, provider, &device_provider.House{ID: 1}, nopNotifier{}, nil, false)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		worker.run(ctx)
+	}()
+	waitPoll(t, provider.polled)
+
+	// повторные запросы не блокируются и схлопываются
+	for i := 0; i < 10; i++ {
+		worker.refresh()
+	}
+	waitPoll(t, provider.polled)
+	select {
+	case <-provider.polled:
+		// второй опрос возможен, если первый запрос был принят до остальных
+	case <-time.After(100 * time.Millisecond):
+	}
+	select {
+	case <-provider.polled:
+		t.Fatal("refresh requests were not collapsed")
+	case <-time.After(100 * time.Millisecond):
+	}
+	provider.mu.Lock()
+	if provider.invalidated == 0 || provider.invalidated != provider.polls-1 {
+		t.Fatalf("each refresh poll must bypass cache: %d polls, %d invalidations", provider.polls, provider.invalidated)
+	}
+	provider.mu.Unlock()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("worker did not stop")
+	}
+}
+
+func waitPoll(t *testing.T, polled <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-polled:
+	case <-time.After(time.Second):
+		t.Fatal("no poll")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"sstcloud-alice-gateway/internal/device_provider"
 	storageModels "sstcloud-alice-gateway/internal/models/storage"
 	"sstcloud-alice-gateway/internal/notifier"
+	"sstcloud-alice-gateway/internal/storage"
 )
 
 type linkWorker struct {
@@ -17,19 +18,25 @@ type linkWorker struct {
 	provider   device_provider.DeviceProvider
 	link       *storageModels.Link
 	notifier   notifier.Notifier
+	storage    storage.Storage
 	workerMap  map[int]*houseWorker
 	workerMapM sync.Mutex
 	wg         sync.WaitGroup
 	cancelFunc context.CancelFunc
+	polled     bool
+	// announce привязка добавлена или изменена на ходу, Алису нужно известить об устройствах уже с первого опроса
+	announce bool
 }
 
-func newLinkWorker(config Config, provider device_provider.DeviceProvider, link *storageModels.Link, notifier notifier.Notifier) *linkWorker {
+func newLinkWorker(config Config, provider device_provider.DeviceProvider, link *storageModels.Link, notifier notifier.Notifier, storage storage.Storage, announce bool) *linkWorker {
 	result := linkWorker{
 		config:    config,
 		provider:  provider,
 		notifier:  notifier,
+		storage:   storage,
 		link:      link,
 		workerMap: map[int]*houseWorker{},
+		announce:  announce,
 	}
 	return &result
 }
@@ -45,17 +52,29 @@ func (w *linkWorker) run(ctx context.Context) {
 
 	r, err := w.provider.Houses(ctx)
 	w.updateHouses(ctx, r, err)
+	period := w.config.MinRequestPeriod
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-time.After(w.config.RequestPeriod):
+		case <-time.After(period):
 			r, err := w.provider.Houses(ctx)
-			w.updateHouses(ctx, r, err)
+			period = w.config.nextPeriod(period, w.updateHouses(ctx, r, err))
 		}
 	}
 }
 
+func (w *linkWorker) refresh(houseID int) bool {
+	w.workerMapM.Lock()
+	defer w.workerMapM.Unlock()
+	worker, exists := w.workerMap[houseID]
+	if !exists {
+		return false
+	}
+	worker.refresh()
+	return true
+}
+
 func (w *linkWorker) stop(ctx context.Context) {
 	if w.cancelFunc != nil {
 		w.cancelFunc()
@@ -88,19 +107,21 @@ func (w *linkWorker) markAllOffline(ctx context.Context, err error) {
 	return
 }
 
-func (w *linkWorker) updateHouses(ctx context.Context, houses []*device_provider.House, err error) {
+func (w *linkWorker) updateHouses(ctx context.Context, houses []*device_provider.House, err error) bool {
 	logger := log.Ctx(ctx)
 	if err != nil {
 		w.markAllOffline(ctx, err)
-		return
+		return false
 	}
+	changed := len(houses) != len(w.workerMap)
 	workerMap := make(map[int]*houseWorker)
 	for _, house := range houses {
 		logger := logger.With().Int("house_id", house.ID).Logger()
 		ctx := logger.WithContext(ctx)
 		worker, exists := w.workerMap[house.ID]
 		if !exists {
-			worker = newHouseWorker(w.config, w.provider, house, w.notifier)
+			changed = true
+			worker = newHouseWorker(w.config, w.provider, house, w.notifier, w.storage, w.polled || w.announce)
 			w.wg.Add(1)
 			go func() {
 				defer func() {
@@ -118,13 +139,23 @@ func (w *linkWorker) updateHouses(ctx context.Context, houses []*device_provider
 		workerMap[house.ID] = worker
 	}
 	w.workerMapM.Lock()
-	defer w.workerMapM.Unlock()
+	removed := false
 	for k, v := range w.workerMap {
 		if _, exists := workerMap[k]; exists {
 			continue
 		}
 		v.markAllOffline(ctx)
 		v.stop(ctx)
+		removed = true
 	}
 	w.workerMap = workerMap
+	w.polled = true
+	w.workerMapM.Unlock()
+	// уведомляем вне блокировки: получатели могут запросить актуальный список устройств
+	if removed {
+		if err := w.notifier.NotifyDevicesListChanged(ctx, w.link.UserID); err != nil {
+			logger.Error().Err(err).Msg("Failed notify devices list changed")
+		}
+	}
+	return changed
 }
@@ -3,15 +3,19 @@ package checker
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/rs/zerolog/log"
 
 	"sstcloud-alice-gateway/internal/device_provider"
+	storageModels "sstcloud-alice-gateway/internal/models/storage"
 	"sstcloud-alice-gateway/internal/notifier"
 	"sstcloud-alice-gateway/internal/storage"
 )
 
-type DeviceFactory func(userID, linkID, username, password string) device_provider.DeviceProvider
+const cleanupPeriod = time.Hour
+
+type DeviceFactory func(link *storageModels.Link) (device_provider.DeviceProvider, error)
 
 type service struct {
 	config        Config
@@ -22,6 +26,10 @@ type service struct {
 	wg            sync.WaitGroup
 	workers       map[string]*linkWorker
 	workersM      sync.Mutex
+	// linksLoaded привязки уже прочитаны при запуске, под workersM
+	linksLoaded bool
+	// reload просит перечитать привязки, не дожидаясь LinksPeriod
+	reload chan struct{}
 }
 
 func New(config Config, storage storage.Storage, deviceFactory DeviceFactory, notifier notifier.Notifier) *service {
@@ -31,6 +39,7 @@ func New(config Config, storage storage.Storage, deviceFactory DeviceFactory, no
 		deviceFactory: deviceFactory,
 		notifier:      notifier,
 		workers:       map[string]*linkWorker{},
+		reload:        make(chan struct{}, 1),
 	}
 }
 
@@ -47,8 +56,64 @@ func (s *service) Run(ctx context.Context, ready func()) error {
 		return err
 	}
 	ready()
-	<-ctx.Done()
-	return nil
+	s.cleanup(ctx)
+	ticker := time.NewTicker(cleanupPeriod)
+	defer ticker.Stop()
+	// LinksPeriod 0 - привязки читаются только при запуске
+	var reloadLinks <-chan time.Time
+	if s.config.LinksPeriod > 0 {
+		linksTicker := time.NewTicker(s.config.LinksPeriod)
+		defer linksTicker.Stop()
+		reloadLinks = linksTicker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-reloadLinks:
+			if err := s.processUpdates(ctx); err != nil {
+				logger.Error().Err(err).Msg("Failed process updates")
+			}
+		case <-s.reload:
+			if err := s.processUpdates(ctx); err != nil {
+				logger.Error().Err(err).Msg("Failed process updates")
+			}
+		case <-ticker.C:
+			s.cleanup(ctx)
+		}
+	}
+}
+
+// cleanup удаляет устаревшие показания и журнал доставки вебхуков
+func (s *service) cleanup(ctx context.Context) {
+	s.cleanupMeasurements(ctx)
+	s.cleanupWebhookDeliveries(ctx)
+}
+
+func (s *service) cleanupMeasurements(ctx context.Context) {
+	if s.config.MeasurementsRetention <= 0 {
+		return
+	}
+	logger := log.Ctx(ctx)
+	deleted, err := s.storage.DeleteMeasurementsBefore(ctx, time.Now().UTC().Add(-s.config.MeasurementsRetention))
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed cleanup measurements")
+		return
+	}
+	logger.Debug().Uint("deleted", deleted).Msg("Measurements cleanup")
+}
+
+func (s *service) cleanupWebhookDeliveries(ctx context.Context) {
+	if s.config.WebhookDeliveriesRetention <= 0 {
+		return
+	}
+	logger := log.Ctx(ctx)
+	deleted, err := s.storage.DeleteWebhookDeliveriesBefore(ctx, time.Now().UTC().Add(-s.config.WebhookDeliveriesRetention))
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed cleanup webhook deliveries")
+		return
+	}
+	logger.Debug().Uint("deleted", deleted).Msg("Webhook deliveries cleanup")
 }
 
 func (s *service) Devices(userID string) []*device_provider.Device {
@@ -64,6 +129,35 @@ func (s *service) Devices(userID string) []*device_provider.Device {
 	return result
 }
 
+func (s *service) Refresh(userID string, houseID int) bool {
+	s.workersM.Lock()
+	defer s.workersM.Unlock()
+	var found bool
+	for _, worker := range s.workers {
+		if worker.link.UserID != userID {
+			continue
+		}
+		if worker.refresh(houseID) {
+			found = true
+		}
+	}
+	return found
+}
+
+// ReloadLinks перечитывает привязки в фоне после их изменения через API: воркеры измененных привязок
+// пересоздаются и после первого опроса просят Алису повторить discovery
+func (s *service) ReloadLinks() {
+	select {
+	case s.reload <- struct{}{}:
+	default:
+	}
+}
+
+// NotifyDevicesListChanged просит повторить discovery без изменения привязок, например после смены настроек устройств
+func (s *service) NotifyDevicesListChanged(ctx context.Context, userID string) error {
+	return s.notifier.NotifyDevicesListChanged(ctx, userID)
+}
+
 func (s *service) processUpdates(ctx context.Context) error {
 	logger := log.Ctx(ctx)
 	links, err := s.storage.Links(ctx)
@@ -80,7 +174,14 @@ func (s *service) processUpdates(ctx context.Context) error {
 			if exist {
 				worker.stop(ctx)
 			}
-			worker = newLinkWorker(s.config, s.deviceFactory(link.UserID, link.ID, link.SSTEmail, link.SSTPassword), link, s.notifier)
+			provider, err := s.deviceFactory(link)
+			if err != nil {
+				logger.Error().Err(err).Str("link_id", link.ID).Msg("Failed create device provider")
+				s.storage.Log(ctx, link.ID, storageModels.Error, "Failed create device provider: "+err.Error())
+				continue
+			}
+			// измененная привязка (язык, представление) и новая привязка после запуска меняют устройства в Алисе
+			worker = newLinkWorker(s.config, provider, link, s.notifier, s.storage, exist || s.linksLoaded)
 			s.wg.Add(1)
 			go func() {
 				defer func() {
@@ -89,7 +190,10 @@ func (s *service) processUpdates(ctx context.Context) error {
 						s.workersM.Unlock()
 						s.wg.Done()
 					}()
-					delete(s.workers, worker.link.ID)
+					// замененный воркер не должен удалить своего преемника
+					if s.workers[worker.link.ID] == worker {
+						delete(s.workers, worker.link.ID)
+					}
 				}()
 				worker.run(ctx)
 			}()
@@ -103,6 +207,7 @@ func (s *service) processUpdates(ctx context.Context) error {
 		worker.stop(ctx)
 	}
 	s.workers = workers
+	s.linksLoaded = true
 	return nil
 }
 
@@ -1,19 +1,25 @@
 package rest
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/rs/zerolog/log"
 
+	"sstcloud-alice-gateway/internal/device_provider"
+	"sstcloud-alice-gateway/internal/i18n"
 	"sstcloud-alice-gateway/internal/mappers"
 	"sstcloud-alice-gateway/internal/models/alice"
+	storageModels "sstcloud-alice-gateway/internal/models/storage"
+	"sstcloud-alice-gateway/internal/profiles"
 	"sstcloud-alice-gateway/pkg/middleware/user"
 )
 
 func (s *service) Action(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	ctx := device_provider.WithCommandSource(r.Context(), string(storageModels.CommandOriginAlice), r.Header.Get(xRequestID))
 	logger := log.Ctx(ctx)
 	var req alice.ActionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -22,80 +28,62 @@ func (s *service) Action(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	devices := s.deviceProvider.Devices(user.User(ctx))
+	settings, err := s.storage.DeviceSettings(ctx, user.User(ctx))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	aliceDevices := alice.Devices{
 		UserID:  user.User(ctx),
 		Devices: make([]alice.Device, 0, len(devices)),
 	}
 	for _, reqDev := range req.Payload.Devices {
-		for _, dev := range devices {
-			if dev.IDStr != reqDev.ID {
-				continue
-			}
-			aliceDevice := alice.Device{
-				ID: dev.IDStr,
-			}
-			logger := log.With().Int("house_id", dev.House.ID).Int("device_id", dev.ID).Logger()
-			for _, capability := range reqDev.Capabilities {
-				logger := logger.With().Str("capability_type", string(capability.Type)).Logger()
-
-				actionResult := alice.ActionResult{
-					Status: alice.ActionResultStatusDone,
-				}
-				switch capability.Type {
-				case alice.CapabilityTypeOnOff:
-					if err := dev.PowerStatus(ctx, capability.State.Value.(bool)); err != nil {
-						logger.Error().Err(err).Msg("Failed set status")
-						actionResult = alice.ActionResult{
-							Status:           alice.ActionResultStatusError,
-							ErrorCode:        alice.ErrorCodeDeviceUnreachable,
-							ErrorDescription: err.Error(),
-						}
-					}
-				case alice.CapabilityTypeRange:
-					if capability.State.Instance != alice.PropertyParameterInstanceTemperature {
-						actionResult = alice.ActionResult{
-							Status:           alice.ActionResultStatusError,
-							ErrorCode:        alice.ErrorCodeInvalidAction,
-							ErrorDescription: fmt.Sprintf("unknown action %s", capability.State.Instance),
-						}
-					} else {
-						value := int(capability.State.Value.(float64))
-						if capability.State.Relative {
-							value = dev.Tempometer.SetDegreesFloor + int(capability.State.Value.(float64))
-						}
-						if value > mappers.MaxTemp || value < mappers.MinTemp {
-							actionResult = alice.ActionResult{
-								Status:           alice.ActionResultStatusError,
-								ErrorCode:        alice.ErrorCodeInvalidAction,
-								ErrorDescription: fmt.Sprintf("value %d not in range %d-%d", value, mappers.MinTemp, mappers.MaxTemp),
-							}
-						} else if err := dev.SetTemperature(ctx, value); err != nil {
-							logger.Error().Err(err).Msg("Failed set status")
-							actionResult = alice.ActionResult{
-								Status:           alice.ActionResultStatusError,
-								ErrorCode:        alice.ErrorCodeDeviceUnreachable,
-								ErrorDescription: err.Error(),
-							}
-						}
-					}
-				default:
-					actionResult = alice.ActionResult{
-						Status:           alice.ActionResultStatusError,
-						ErrorCode:        alice.ErrorCodeInvalidAction,
-						ErrorDescription: fmt.Sprintf("unknown action %s", capability.Type),
-					}
-				}
-				aliceDevice.Capabilities = append(aliceDevice.Capabilities, alice.CapabilityResponse{
-					Type: capability.Type,
-					State: alice.CapabilityResponseState{
-						Instance:     capability.State.Instance,
-						ActionResult: actionResult,
-					},
-				})
-			}
-			aliceDevices.Devices = append(aliceDevices.Devices, aliceDevice)
+		id, err := mappers.ParseDeviceID(reqDev.ID)
+		var dev *device_provider.Device
+		// скрытое устройство для Алисы не существует
+		if err == nil && id.Sensor == "" && !mappers.IsHidden(settings, reqDev.ID) {
+			dev = mappers.FindDevice(devices, id)
+		}
+		if dev == nil {
+			aliceDevices.Devices = append(aliceDevices.Devices, alice.Device{
+				ID: reqDev.ID,
+				ActionResult: &alice.ActionResult{
+					Status:           alice.ActionResultStatusError,
+					ErrorCode:        alice.ErrorCodeDeviceNotFound,
+					ErrorDescription: fmt.Sprintf(userMessages(devices).DeviceNotFound, reqDev.ID),
+				},
+			})
+			continue
 		}
+		messages := i18n.For(dev.House.Language)
+		if !dev.Connected {
+			aliceDevices.Devices = append(aliceDevices.Devices, alice.Device{
+				ID: reqDev.ID,
+				ActionResult: &alice.ActionResult{
+					Status:           alice.ActionResultStatusError,
+					ErrorCode:        alice.ErrorCodeDeviceUnreachable,
+					ErrorDescription: messages.DeviceOffline,
+				},
+			})
+			continue
+		}
+		aliceDevice := alice.Device{
+			ID: reqDev.ID,
+		}
+		logger := log.With().Int("house_id", dev.House.ID).Int("device_id", dev.ID).Logger()
+		for _, capability := range reqDev.Capabilities {
+			logger := logger.With().Str("capability_type", string(capability.Type)).Logger()
+			aliceDevice.Capabilities = append(aliceDevice.Capabilities, alice.CapabilityResponse{
+				Type: capability.Type,
+				State: alice.CapabilityResponseState{
+					Instance:     capability.State.Instance,
+					ActionResult: applyCapability(logger.WithContext(ctx), dev, capability, messages),
+				},
+			})
+		}
+		aliceDevices.Devices = append(aliceDevices.Devices, aliceDevice)
+		s.deviceProvider.Refresh(user.User(ctx), dev.House.ID)
 	}
 
 	if err := json.NewEncoder(w).Encode(alice.Response{
@@ -106,3 +94,81 @@ func (s *service) Action(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
+
+func applyCapability(ctx context.Context, dev *device_provider.Device, capability alice.CapabilityRequest, messages *i18n.Messages) alice.ActionResult {
+	logger := log.Ctx(ctx)
+	action, err := capability.Decode()
+	if err != nil {
+		var validationErr *alice.ValidationError
+		if errors.As(err, &validationErr) {
+			return invalidValue(fmt.Sprintf(messages.InvalidValue, validationErr))
+		}
+		return unknownAction(fmt.Sprintf(messages.UnknownAction, capability.Type))
+	}
+	profile := profiles.For(dev.Model)
+	switch a := action.(type) {
+	case alice.OnOffAction:
+		if !profile.Has(profiles.CapabilityOnOff) {
+			return unknownAction(fmt.Sprintf(messages.UnknownAction, capability.Type))
+		}
+		err = dev.PowerStatus(ctx, a.Value)
+	case alice.RangeAction:
+		if a.Instance != alice.CapabilityRangeInstanceTemperature || !profile.Has(profiles.CapabilityTemperature) {
+			return unknownAction(fmt.Sprintf(messages.UnknownAction, a.Instance))
+		}
+		value, convErr := device_provider.TemperatureFromFloat(a.Value)
+		if convErr != nil {
+			return invalidValue(fmt.Sprintf(messages.InvalidValue, convErr))
+		}
+		if a.Relative {
+			value += dev.Tempometer.SetDegreesFloor
+		}
+		temp, ok := dev.Limits.Normalize(value)
+		if !ok {
+			return invalidValue(fmt.Sprintf(messages.ValueOutOfRange, temp, dev.Limits.Min, dev.Limits.Max))
+		}
+		err = dev.SetTemperature(ctx, temp)
+	}
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed set status")
+		return commandError(err, messages)
+	}
+	return alice.ActionResult{
+		Status: alice.ActionResultStatusDone,
+	}
+}
+
+func unknownAction(description string) alice.ActionResult {
+	return alice.ActionResult{
+		Status:           alice.ActionResultStatusError,
+		ErrorCode:        alice.ErrorCodeInvalidAction,
+		ErrorDescription: description,
+	}
+}
+
+func invalidValue(description string) alice.ActionResult {
+	return alice.ActionResult{
+		Status:           alice.ActionResultStatusError,
+		ErrorCode:        alice.ErrorCodeInvalidValue,
+		ErrorDescription: description,
+	}
+}
+
+// commandError переводит ошибку провайдера в результат действия
+func commandError(err error, messages *i18n.Messages) alice.ActionResult {
+	if errors.Is(err, device_provider.ErrInvalidTemperature) {
+		return invalidValue(fmt.Sprintf(messages.InvalidValue, err))
+	}
+	if errors.Is(err, device_provider.ErrDeviceBusy) {
+		return alice.ActionResult{
+			Status:           alice.ActionResultStatusError,
+			ErrorCode:        alice.ErrorCodeDeviceBusy,
+			ErrorDescription: messages.DeviceBusy,
+		}
+	}
+	return alice.ActionResult{
+		Status:           alice.ActionResultStatusError,
+		ErrorCode:        alice.ErrorCodeDeviceUnreachable,
+		ErrorDescription: fmt.Sprintf(messages.DeviceUnreachable, err),
+	}
+}
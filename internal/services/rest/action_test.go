@@ -0,0 +1,332 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"sstcloud-alice-gateway/internal/device_provider"
+	"sstcloud-alice-gateway/internal/models/alice"
+	storageModels "sstcloud-alice-gateway/internal/models/storage"
+	"sstcloud-alice-gateway/internal/storage/memory"
+	"sstcloud-alice-gateway/pkg/middleware/user"
+)
+
+type fakeProvider struct {
+	err      error
+	power    []bool
+	setpoint []device_provider.Temperature
+}
+
+func (p *fakeProvider) Init(context.Context) error { return nil }
+
+func (p *fakeProvider) Houses(context.Context) ([]*device_provider.House, error) { return nil, nil }
+
+func (p *fakeProvider) Devices(context.Context, *device_provider.House) ([]*device_provider.Device, error) {
+	return nil, nil
+}
+
+func (p *fakeProvider) SetTemperature(_ context.Context, _ *device_provider.Device, temp device_provider.Temperature) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.setpoint = append(p.setpoint, temp)
+	return nil
+}
+
+func (p *fakeProvider) PowerStatus(_ context.Context, _ *device_provider.Device, power bool) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.power = append(p.power, power)
+	return nil
+}
+
+type fakeDevices struct {
+	devices []*device_provider.Device
+}
+
+func (d *fakeDevices) Devices(string) []*device_provider.Device { return d.devices }
+
+func (d *fakeDevices) Refresh(string, int) bool { return true }
+
+func (d *fakeDevices) ReloadLinks() {}
+
+func (d *fakeDevices) NotifyDevicesListChanged(context.Context, string) error { return nil }
+
+func TestAction(t *testing.T) {
+	tests := []struct {
+		name         string
+		payload      string
+		connected    bool
+		providerErr  error
+		want         []alice.ActionResult
+		wantPower    []bool
+		wantSetpoint []device_provider.Temperature
+	}{
+		{
+			name:      "turn on",
+			payload:   `{"payload":{"devices":[{"id":"1_2","custom_data":{},"capabilities":[{"type":"devices.capabilities.on_off","state":{"instance":"on","value":true}}]}]}}`,
+			connected: true,
+			want:      []alice.ActionResult{{Status: alice.ActionResultStatusDone}},
+			wantPower: []bool{true},
+		},
+		{
+			name:         "set temperature",
+			payload:      `{"payload":{"devices":[{"id":"1_2","capabilities":[{"type":"devices.capabilities.range","state":{"instance":"temperature","value":30}}]}]}}`,
+			connected:    true,
+			want:         []alice.ActionResult{{Status: alice.ActionResultStatusDone}},
+			wantSetpoint: []device_provider.Temperature{device_provider.Degrees(30)},
+		},
+		{
+			name:         "relative temperature",
+			payload:      `{"payload":{"devices":[{"id":"1_2","capabilities":[{"type":"devices.capabilities.range","state":{"instance":"temperature","value":-3,"relative":true}}]}]}}`,
+			connected:    true,
+			want:         []alice.ActionResult{{Status: alice.ActionResultStatusDone}},
+			wantSetpoint: []device_provider.Temperature{device_provider.Degrees(22)},
+		},
+		{
+			name:      "power and temperature",
+			payload:   `{"payload":{"devices":[{"id":"1_2","capabilities":[{"type":"devices.capabilities.on_off","state":{"instance":"on","value":false}},{"type":"devices.capabilities.range","state":{"instance":"temperature","value":20}}]}]}}`,
+			connected: true,
+			want: []alice.ActionResult{
+				{Status: alice.ActionResultStatusDone},
+				{Status: alice.ActionResultStatusDone},
+			},
+			wantPower:    []bool{false},
+			wantSetpoint: []device_provider.Temperature{device_provider.Degrees(20)},
+		},
+		{
+			name:      "string power value",
+			payload:   `{"payload":{"devices":[{"id":"1_2","capabilities":[{"type":"devices.capabilities.on_off","state":{"instance":"on","value":"yes"}}]}]}}`,
+			connected: true,
+			want:      []alice.ActionResult{{Status: alice.ActionResultStatusError, ErrorCode: alice.ErrorCodeInvalidValue}},
+		},
+		{
+			name:         "fractional temperature rounded to model precision",
+			payload:      `{"payload":{"devices":[{"id":"1_2","capabilities":[{"type":"devices.capabilities.range","state":{"instance":"temperature","value":22.5}}]}]}}`,
+			connected:    true,
+			want:         []alice.ActionResult{{Status: alice.ActionResultStatusDone}},
+			wantSetpoint: []device_provider.Temperature{device_provider.Degrees(23)},
+		},
+		{
+			name:      "rounded temperature out of range",
+			payload:   `{"payload":{"devices":[{"id":"1_2","capabilities":[{"type":"devices.capabilities.range","state":{"instance":"temperature","value":45.6}}]}]}}`,
+			connected: true,
+			want:      []alice.ActionResult{{Status: alice.ActionResultStatusError, ErrorCode: alice.ErrorCodeInvalidValue}},
+		},
+		{
+			name:      "temperature out of range",
+			payload:   `{"payload":{"devices":[{"id":"1_2","capabilities":[{"type":"devices.capabilities.range","state":{"instance":"temperature","value":1e300}}]}]}}`,
+			connected: true,
+			want:      []alice.ActionResult{{Status: alice.ActionResultStatusError, ErrorCode: alice.ErrorCodeInvalidValue}},
+		},
+		{
+			name:      "unknown range instance",
+			payload:   `{"payload":{"devices":[{"id":"1_2","capabilities":[{"type":"devices.capabilities.range","state":{"instance":"brightness","value":50}}]}]}}`,
+			connected: true,
+			want:      []alice.ActionResult{{Status: alice.ActionResultStatusError, ErrorCode: alice.ErrorCodeInvalidAction}},
+		},
+		{
+			name:      "unsupported capability",
+			payload:   `{"payload":{"devices":[{"id":"1_2","capabilities":[{"type":"devices.capabilities.mode","state":{"instance":"program","value":"auto"}}]}]}}`,
+			connected: true,
+			want:      []alice.ActionResult{{Status: alice.ActionResultStatusError, ErrorCode: alice.ErrorCodeInvalidAction}},
+		},
+		{
+			name:        "busy device",
+			payload:     `{"payload":{"devices":[{"id":"1_2","capabilities":[{"type":"devices.capabilities.on_off","state":{"instance":"on","value":true}}]}]}}`,
+			connected:   true,
+			providerErr: device_provider.ErrDeviceBusy,
+			want:        []alice.ActionResult{{Status: alice.ActionResultStatusError, ErrorCode: alice.ErrorCodeDeviceBusy}},
+		},
+		{
+			name:        "setpoint rejected by provider",
+			payload:     `{"payload":{"devices":[{"id":"1_2","capabilities":[{"type":"devices.capabilities.range","state":{"instance":"temperature","value":30}}]}]}}`,
+			connected:   true,
+			providerErr: fmt.Errorf("%w: 30.5 is not a whole degree", device_provider.ErrInvalidTemperature),
+			want:        []alice.ActionResult{{Status: alice.ActionResultStatusError, ErrorCode: alice.ErrorCodeInvalidValue}},
+		},
+		{
+			name:        "provider failure",
+			payload:     `{"payload":{"devices":[{"id":"1_2","capabilities":[{"type":"devices.capabilities.on_off","state":{"instance":"on","value":true}}]}]}}`,
+			connected:   true,
+			providerErr: errors.New("timeout"),
+			want:        []alice.ActionResult{{Status: alice.ActionResultStatusError, ErrorCode: alice.ErrorCodeDeviceUnreachable}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &fakeProvider{err: tt.providerErr}
+			s := newTestService(t, testDevice(provider, tt.connected))
+			response := doAction(t, s, tt.payload)
+			if len(response.Payload.Devices) != 1 {
+				t.Fatalf("expected 1 device, got %d", len(response.Payload.Devices))
+			}
+			capabilities := response.Payload.Devices[0].Capabilities
+			if len(capabilities) != len(tt.want) {
+				t.Fatalf("expected %d capabilities, got %d", len(tt.want), len(capabilities))
+			}
+			for i, want := range tt.want {
+				got := capabilities[i].State.ActionResult
+				if got.Status != want.Status || got.ErrorCode != want.ErrorCode {
+					t.Errorf("capability %d: got %s/%s, want %s/%s", i, got.Status, got.ErrorCode, want.Status, want.ErrorCode)
+				}
+			}
+			if !equalSlices(provider.power, tt.wantPower) {
+				t.Errorf("power calls %v, want %v", provider.power, tt.wantPower)
+			}
+			if !equalSlices(provider.setpoint, tt.wantSetpoint) {
+				t.Errorf("setpoint calls %v, want %v", provider.setpoint, tt.wantSetpoint)
+			}
+		})
+	}
+}
+
+func TestActionDeviceErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		id        string
+		connected bool
+		hidden    bool
+		want      alice.ErrorCode
+	}{
+		{name: "unknown device", id: "1_3", connected: true, want: alice.ErrorCodeDeviceNotFound},
+		{name: "malformed id", id: "garbage", connected: true, want: alice.ErrorCodeDeviceNotFound},
+		{name: "sensor id", id: "1_2_air", connected: true, want: alice.ErrorCodeDeviceNotFound},
+		{name: "offline device", id: "1_2", connected: false, want: alice.ErrorCodeDeviceUnreachable},
+		{name: "hidden device", id: "1_2", connected: true, hidden: true, want: alice.ErrorCodeDeviceNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &fakeProvider{}
+			s := newTestService(t, testDevice(provider, tt.connected))
+			if tt.hidden {
+				hideDevice(t, s, tt.id)
+			}
+			payload := `{"payload":{"devices":[{"id":"` + tt.id + `","capabilities":[{"type":"devices.capabilities.on_off","state":{"instance":"on","value":true}}]}]}}`
+			response := doAction(t, s, payload)
+			if len(response.Payload.Devices) != 1 {
+				t.Fatalf("expected 1 device, got %d", len(response.Payload.Devices))
+			}
+			device := response.Payload.Devices[0]
+			if device.ID != tt.id {
+				t.Errorf("got id %s, want %s", device.ID, tt.id)
+			}
+			if device.ActionResult == nil || device.ActionResult.ErrorCode != tt.want {
+				t.Fatalf("got %+v, want %s", device.ActionResult, tt.want)
+			}
+			if len(provider.power) != 0 {
+				t.Errorf("unexpected provider calls %v", provider.power)
+			}
+		})
+	}
+}
+
+func TestActionMalformedBody(t *testing.T) {
+	s := newTestService(t)
+	for _, payload := range []string{
+		`{"payload":{"devices":{}}}`,
+		`{"payload":{"devices":[{"id":"1_2","capabilities":[{"type":"devices.capabilities.on_off","state":"on"}]}]}}`,
+		`not json`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/v1.0/user/devices/action", strings.NewReader(payload))
+		rec := httptest.NewRecorder()
+		user.Middleware(http.HandlerFunc(s.Action)).ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("payload %s: got status %d, want %d", payload, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+// newTestService сервис с хранилищем в памяти и заданными устройствами пользователя "user"
+func newTestService(t *testing.T, devices ...*device_provider.Device) *service {
+	t.Helper()
+	store := memory.New("")
+	if err := store.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return &service{deviceProvider: &fakeDevices{devices: devices}, storage: store}
+}
+
+func hideDevice(t *testing.T, s *service, deviceID string) {
+	t.Helper()
+	if err := s.storage.SaveDeviceSetting(context.Background(), &storageModels.DeviceSetting{UserID: "user", DeviceID: deviceID, Hidden: true}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testDevice(provider device_provider.DeviceProvider, connected bool) *device_provider.Device {
+	return &device_provider.Device{
+		House: &device_provider.House{
+			ID:             1,
+			Name:           "Дом",
+			DeviceProvider: provider,
+		},
+		ID:        2,
+		IDStr:     "1_2",
+		Name:      "Теплый пол",
+		Enabled:   true,
+		Connected: connected,
+		Tempometer: device_provider.Tempometer{
+			SetDegreesFloor: device_provider.Degrees(25),
+		},
+		Limits: device_provider.Limits{
+			Min:       device_provider.Degrees(12),
+			Max:       device_provider.Degrees(45),
+			Precision: device_provider.Degrees(1),
+			Rounding:  device_provider.RoundNearest,
+		},
+	}
+}
+
+type actionResponse struct {
+	RequestID string `json:"request_id"`
+	Payload   struct {
+		Devices []struct {
+			ID           string              `json:"id"`
+			ActionResult *alice.ActionResult `json:"action_result"`
+			Capabilities []struct {
+				Type  alice.CapabilityType          `json:"type"`
+				State alice.CapabilityResponseState `json:"state"`
+			} `json:"capabilities"`
+		} `json:"devices"`
+	} `json:"payload"`
+}
+
+func doAction(t *testing.T, s *service, payload string) actionResponse {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/v1.0/user/devices/action", strings.NewReader(payload))
+	req.Header.Set("X-User-Id", "user")
+	req.Header.Set(xRequestID, "request")
+	rec := httptest.NewRecorder()
+	user.Middleware(http.HandlerFunc(s.Action)).ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body.String())
+	}
+	var response actionResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if response.RequestID != "request" {
+		t.Errorf("got request id %s", response.RequestID)
+	}
+	return response
+}
+
+func equalSlices[T comparable](a, b []T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
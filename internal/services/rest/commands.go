@@ -0,0 +1,91 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	storagePkg "sstcloud-alice-gateway/internal/storage"
+	"sstcloud-alice-gateway/pkg/middleware/user"
+)
+
+const (
+	defaultCommandsLimit = 100
+	maxCommandsLimit     = 1000
+)
+
+type commandResponse struct {
+	ID         string    `json:"id"`
+	DeviceID   string    `json:"device_id"`
+	DeviceName string    `json:"device_name"`
+	Command    string    `json:"command"`
+	OldValue   string    `json:"old_value"`
+	NewValue   string    `json:"new_value"`
+	Origin     string    `json:"origin"`
+	RequestID  string    `json:"request_id,omitempty"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	Time       time.Time `json:"time"`
+}
+
+func (s *service) Commands(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
+	query := r.URL.Query()
+	filter := storagePkg.CommandsFilter{
+		UserID:   user.User(ctx),
+		DeviceID: query.Get("device_id"),
+		Limit:    defaultCommandsLimit,
+	}
+	for key, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		v := query.Get(key)
+		if v == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "invalid "+key+": "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		*target = parsed
+	}
+	if v := query.Get("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = parsed
+	}
+	if filter.Limit > maxCommandsLimit {
+		filter.Limit = maxCommandsLimit
+	}
+	commands, err := s.storage.Commands(ctx, filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	result := make([]commandResponse, 0, len(commands))
+	for _, c := range commands {
+		result = append(result, commandResponse{
+			ID:         c.ID,
+			DeviceID:   c.DeviceID,
+			DeviceName: c.DeviceName,
+			Command:    string(c.Command),
+			OldValue:   c.OldValue,
+			NewValue:   c.NewValue,
+			Origin:     string(c.Origin),
+			RequestID:  c.RequestID,
+			Status:     string(c.Status),
+			Error:      c.Error,
+			Time:       c.Time,
+		})
+	}
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		logger.Error().Err(err).Msg("Failed marshal response")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
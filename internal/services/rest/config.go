@@ -2,4 +2,6 @@ package rest
 
 type Config struct {
 	Address string `env:"HTTP_ADDRESS,default=:80"`
+	// WebhookAllowPrivateAddresses то же, что WEBHOOK_ALLOW_PRIVATE_ADDRESSES у отправителя вебхуков
+	WebhookAllowPrivateAddresses bool `env:"WEBHOOK_ALLOW_PRIVATE_ADDRESSES"`
 }
@@ -0,0 +1,125 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+
+	"sstcloud-alice-gateway/internal/mappers"
+	storageModels "sstcloud-alice-gateway/internal/models/storage"
+	storagePkg "sstcloud-alice-gateway/internal/storage"
+	"sstcloud-alice-gateway/pkg/middleware/user"
+)
+
+const maxDeviceSettingLen = 100
+
+type deviceSettingRequest struct {
+	Name        string `json:"name"`
+	Room        string `json:"room"`
+	Description string `json:"description"`
+	Hidden      bool   `json:"hidden"`
+}
+
+type deviceSettingResponse struct {
+	DeviceID    string    `json:"device_id"`
+	Name        string    `json:"name,omitempty"`
+	Room        string    `json:"room,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Hidden      bool      `json:"hidden"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func newDeviceSettingResponse(setting *storageModels.DeviceSetting) deviceSettingResponse {
+	return deviceSettingResponse{
+		DeviceID:    setting.DeviceID,
+		Name:        setting.Name,
+		Room:        setting.Room,
+		Description: setting.Description,
+		Hidden:      setting.Hidden,
+		UpdatedAt:   setting.UpdatedAt,
+	}
+}
+
+func (s *service) DeviceSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
+	settings, err := s.storage.DeviceSettings(ctx, user.User(ctx))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	result := make([]deviceSettingResponse, 0, len(settings))
+	for _, setting := range settings {
+		result = append(result, newDeviceSettingResponse(setting))
+	}
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		logger.Error().Err(err).Msg("Failed marshal response")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *service) SaveDeviceSetting(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
+	var req deviceSettingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error().Err(err).Msg("Failed unmarshal data")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	deviceID := chi.URLParam(r, "device_id")
+	if _, err := mappers.ParseDeviceID(deviceID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, v := range []string{req.Name, req.Room, req.Description} {
+		if utf8.RuneCountInString(v) > maxDeviceSettingLen {
+			http.Error(w, "value is too long", http.StatusBadRequest)
+			return
+		}
+	}
+	setting := storageModels.DeviceSetting{
+		UserID:      user.User(ctx),
+		DeviceID:    deviceID,
+		Name:        req.Name,
+		Room:        req.Room,
+		Description: req.Description,
+		Hidden:      req.Hidden,
+	}
+	if err := s.storage.SaveDeviceSetting(ctx, &setting); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.notifySettingsChanged(ctx, setting.UserID)
+	if err := json.NewEncoder(w).Encode(newDeviceSettingResponse(&setting)); err != nil {
+		logger.Error().Err(err).Msg("Failed marshal response")
+	}
+}
+
+func (s *service) DeleteDeviceSetting(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if err := s.storage.DeleteDeviceSetting(ctx, user.User(ctx), chi.URLParam(r, "device_id")); err != nil {
+		if errors.Is(err, storagePkg.ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.notifySettingsChanged(ctx, user.User(ctx))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// notifySettingsChanged просит Алису повторить discovery: имена, комнаты и скрытые устройства берутся из настроек.
+// Настройка уже сохранена, поэтому ошибка уведомления только пишется в лог.
+func (s *service) notifySettingsChanged(ctx context.Context, userID string) {
+	if err := s.deviceProvider.NotifyDevicesListChanged(ctx, userID); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Failed notify devices list changed")
+	}
+}
@@ -24,6 +24,12 @@ func (s *service) Devices(w http.ResponseWriter, r *http.Request) {
 	for _, d := range devices {
 		aliceDevices.Devices = append(aliceDevices.Devices, mappers.DeviceToAlice(d)...)
 	}
+	settings, err := s.storage.DeviceSettings(ctx, user.User(ctx))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	aliceDevices.Devices = mappers.ApplySettings(aliceDevices.Devices, settings)
 
 	if err := json.NewEncoder(w).Encode(alice.Response{
 		RequestID: r.Header.Get(xRequestID),
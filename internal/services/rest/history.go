@@ -0,0 +1,129 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+
+	storageModels "sstcloud-alice-gateway/internal/models/storage"
+	"sstcloud-alice-gateway/pkg/middleware/user"
+)
+
+const (
+	defaultHistoryRange  = time.Hour * 24
+	defaultHistoryPoints = 288
+	maxHistoryPoints     = 5000
+)
+
+type historyResponse struct {
+	DeviceID   string         `json:"device_id"`
+	From       time.Time      `json:"from"`
+	To         time.Time      `json:"to"`
+	Resolution string         `json:"resolution"`
+	Points     []historyPoint `json:"points"`
+}
+
+type historyPoint struct {
+	Time            time.Time `json:"time"`
+	DegreesAir      float64   `json:"degrees_air"`
+	DegreesFloor    float64   `json:"degrees_floor"`
+	SetDegreesFloor float64   `json:"set_degrees_floor"`
+	Enabled         bool      `json:"enabled"`
+	Samples         int       `json:"samples"`
+}
+
+func (s *service) History(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
+	query := r.URL.Query()
+	to := time.Now().UTC()
+	if v := query.Get("to"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "invalid to: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		to = parsed.UTC()
+	}
+	from := to.Add(-defaultHistoryRange)
+	if v := query.Get("from"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "invalid from: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		from = parsed.UTC()
+	}
+	if !from.Before(to) {
+		http.Error(w, "from must be before to", http.StatusBadRequest)
+		return
+	}
+	resolution := (to.Sub(from) / defaultHistoryPoints).Truncate(time.Minute)
+	if v := query.Get("resolution"); v != "" {
+		parsed, err := time.ParseDuration(v)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "invalid resolution", http.StatusBadRequest)
+			return
+		}
+		resolution = parsed
+	}
+	if resolution < time.Minute {
+		resolution = time.Minute
+	}
+	if to.Sub(from)/resolution > maxHistoryPoints {
+		http.Error(w, "too many points, increase resolution", http.StatusBadRequest)
+		return
+	}
+	deviceID := chi.URLParam(r, "device_id")
+	measurements, err := s.storage.Measurements(ctx, user.User(ctx), deviceID, from, to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(historyResponse{
+		DeviceID:   deviceID,
+		From:       from,
+		To:         to,
+		Resolution: resolution.String(),
+		Points:     downsample(measurements, from, resolution),
+	}); err != nil {
+		logger.Error().Err(err).Msg("Failed marshal response")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// downsample усредняет показания датчиков внутри интервала, уставка и питание берутся последние.
+// measurements должны быть отсортированы по времени.
+func downsample(measurements []*storageModels.Measurement, from time.Time, resolution time.Duration) []historyPoint {
+	result := make([]historyPoint, 0)
+	var (
+		air, floor float64
+		bucket     time.Time
+	)
+	for _, m := range measurements {
+		start := from.Add(m.Time.Sub(from) / resolution * resolution)
+		if len(result) == 0 || !start.Equal(bucket) {
+			if len(result) > 0 {
+				last := &result[len(result)-1]
+				last.DegreesAir, last.DegreesFloor = air/float64(last.Samples), floor/float64(last.Samples)
+			}
+			bucket = start
+			air, floor = 0, 0
+			result = append(result, historyPoint{Time: start})
+		}
+		point := &result[len(result)-1]
+		air += float64(m.DegreesAir)
+		floor += float64(m.DegreesFloor)
+		point.SetDegreesFloor = m.SetDegreesFloor
+		point.Enabled = m.Enabled
+		point.Samples++
+	}
+	if len(result) > 0 {
+		last := &result[len(result)-1]
+		last.DegreesAir, last.DegreesFloor = air/float64(last.Samples), floor/float64(last.Samples)
+	}
+	return result
+}
@@ -0,0 +1,97 @@
+package rest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	storageModels "sstcloud-alice-gateway/internal/models/storage"
+)
+
+func TestDownsample(t *testing.T) {
+	from := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time {
+		return from.Add(time.Duration(minutes) * time.Minute)
+	}
+	measurement := func(minutes int, air, floor, set float64, enabled bool) *storageModels.Measurement {
+		return &storageModels.Measurement{Time: at(minutes), DegreesAir: air, DegreesFloor: floor, SetDegreesFloor: set, Enabled: enabled}
+	}
+	tests := []struct {
+		name         string
+		measurements []*storageModels.Measurement
+		resolution   time.Duration
+		want         []historyPoint
+	}{
+		{
+			name:       "no measurements",
+			resolution: 10 * time.Minute,
+			want:       []historyPoint{},
+		},
+		{
+			name: "sensors averaged, setpoint and power from last row",
+			measurements: []*storageModels.Measurement{
+				measurement(0, 20, 24, 25, true),
+				measurement(5, 22, 26, 27, false),
+			},
+			resolution: 10 * time.Minute,
+			want: []historyPoint{
+				{Time: at(0), DegreesAir: 21, DegreesFloor: 25, SetDegreesFloor: 27, Enabled: false, Samples: 2},
+			},
+		},
+		{
+			name: "bucket edge starts next bucket",
+			measurements: []*storageModels.Measurement{
+				measurement(0, 20, 24, 25, true),
+				measurement(9, 21, 25, 25, true),
+				measurement(10, 30, 30, 30, true),
+			},
+			resolution: 10 * time.Minute,
+			want: []historyPoint{
+				{Time: at(0), DegreesAir: 20.5, DegreesFloor: 24.5, SetDegreesFloor: 25, Enabled: true, Samples: 2},
+				{Time: at(10), DegreesAir: 30, DegreesFloor: 30, SetDegreesFloor: 30, Enabled: true, Samples: 1},
+			},
+		},
+		{
+			name: "empty buckets are skipped",
+			measurements: []*storageModels.Measurement{
+				measurement(1, 20, 24, 25, true),
+				measurement(35, 22, 26, 25, true),
+			},
+			resolution: 10 * time.Minute,
+			want: []historyPoint{
+				{Time: at(0), DegreesAir: 20, DegreesFloor: 24, SetDegreesFloor: 25, Enabled: true, Samples: 1},
+				{Time: at(30), DegreesAir: 22, DegreesFloor: 26, SetDegreesFloor: 25, Enabled: true, Samples: 1},
+			},
+		},
+		{
+			name: "buckets aligned to from, not to the clock",
+			measurements: []*storageModels.Measurement{
+				measurement(7, 20, 24, 25, true),
+				measurement(13, 22, 26, 25, true),
+			},
+			resolution: 7 * time.Minute,
+			want: []historyPoint{
+				{Time: at(7), DegreesAir: 21, DegreesFloor: 25, SetDegreesFloor: 25, Enabled: true, Samples: 2},
+			},
+		},
+		{
+			// офлайн устройства не записываются, поэтому в строке всегда есть оба датчика, а нулевое значение - реальное показание
+			name: "zero reading is averaged",
+			measurements: []*storageModels.Measurement{
+				measurement(0, 0, 24, 25, true),
+				measurement(1, 2, 26, 25, true),
+			},
+			resolution: 10 * time.Minute,
+			want: []historyPoint{
+				{Time: at(0), DegreesAir: 1, DegreesFloor: 25, SetDegreesFloor: 25, Enabled: true, Samples: 2},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := downsample(tt.measurements, from, tt.resolution); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
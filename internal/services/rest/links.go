@@ -0,0 +1,110 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+
+	"sstcloud-alice-gateway/internal/device_provider"
+	"sstcloud-alice-gateway/internal/i18n"
+	storagePkg "sstcloud-alice-gateway/internal/storage"
+	"sstcloud-alice-gateway/pkg/middleware/user"
+)
+
+type linkResponse struct {
+	ID       string `json:"id"`
+	EMail    string `json:"email"`
+	Provider string `json:"provider"`
+	Language string `json:"language"`
+	// Layout пустое значение означает представление по умолчанию
+	Layout string `json:"device_layout"`
+}
+
+type linkLanguageRequest struct {
+	Language string `json:"language"`
+}
+
+type linkLayoutRequest struct {
+	Layout string `json:"device_layout"`
+}
+
+func (s *service) Links(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
+	links, err := s.storage.UserLinks(ctx, user.User(ctx))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	result := make([]linkResponse, 0, len(links))
+	for _, link := range links {
+		language, _ := i18n.Parse(link.Language)
+		result = append(result, linkResponse{
+			ID:       link.ID,
+			EMail:    link.Email(),
+			Provider: link.ProviderName(),
+			Language: string(language),
+			Layout:   link.Layout,
+		})
+	}
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		logger.Error().Err(err).Msg("Failed marshal response")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *service) SetLinkLanguage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
+	var req linkLanguageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error().Err(err).Msg("Failed unmarshal data")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	language, ok := i18n.Parse(req.Language)
+	if !ok {
+		http.Error(w, "unsupported language", http.StatusBadRequest)
+		return
+	}
+	if err := s.storage.SetLinkLanguage(ctx, user.User(ctx), chi.URLParam(r, "link_id"), string(language)); err != nil {
+		if errors.Is(err, storagePkg.ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// имена и состав устройств изменились, Алиса должна повторить discovery
+	s.deviceProvider.ReloadLinks()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *service) SetLinkLayout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
+	var req linkLayoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error().Err(err).Msg("Failed unmarshal data")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, ok := device_provider.ParseLayout(req.Layout, device_provider.LayoutSplit); !ok {
+		http.Error(w, "unsupported device layout", http.StatusBadRequest)
+		return
+	}
+	if err := s.storage.SetLinkLayout(ctx, user.User(ctx), chi.URLParam(r, "link_id"), req.Layout); err != nil {
+		if errors.Is(err, storagePkg.ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// имена и состав устройств изменились, Алиса должна повторить discovery
+	s.deviceProvider.ReloadLinks()
+	w.WriteHeader(http.StatusNoContent)
+}
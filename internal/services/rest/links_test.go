@@ -0,0 +1,46 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	storageModels "sstcloud-alice-gateway/internal/models/storage"
+	"sstcloud-alice-gateway/pkg/middleware/user"
+)
+
+func TestLinksEmail(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	for _, link := range []*storageModels.Link{
+		{UserID: "user", SSTEmail: "legacy@example.com", SSTPassword: "secret"},
+		{UserID: "user", Provider: "sst", Credentials: `{"email":"new@example.com","password":"secret"}`},
+	} {
+		if err := s.storage.AddLink(ctx, link); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/links", nil)
+	req.Header.Set("X-User-Id", "user")
+	rec := httptest.NewRecorder()
+	user.Middleware(http.HandlerFunc(s.Links)).ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body.String())
+	}
+	var links []map[string]string
+	if err := json.NewDecoder(rec.Body).Decode(&links); err != nil {
+		t.Fatal(err)
+	}
+	emails := map[string]bool{}
+	for _, link := range links {
+		if _, exists := link["password"]; exists {
+			t.Fatalf("password leaked: %v", link)
+		}
+		emails[link["email"]] = true
+	}
+	if len(links) != 2 || !emails["legacy@example.com"] || !emails["new@example.com"] {
+		t.Fatalf("unexpected links %v", links)
+	}
+}
@@ -2,11 +2,13 @@ package rest
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
-	"strings"
 
 	"github.com/rs/zerolog/log"
 
+	"sstcloud-alice-gateway/internal/device_provider"
+	"sstcloud-alice-gateway/internal/i18n"
 	"sstcloud-alice-gateway/internal/mappers"
 	"sstcloud-alice-gateway/internal/models/alice"
 	"sstcloud-alice-gateway/pkg/middleware/user"
@@ -22,30 +24,26 @@ func (s *service) Query(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	devices := s.deviceProvider.Devices(user.User(ctx))
+	settings, err := s.storage.DeviceSettings(ctx, user.User(ctx))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	aliceDevices := alice.Devices{
 		UserID:  user.User(ctx),
-		Devices: make([]alice.Device, 0, len(devices)),
+		Devices: make([]alice.Device, 0, len(req.Devices)),
 	}
 	for _, reqDev := range req.Devices {
-		id := reqDev.ID
-		parts := strings.Split(reqDev.ID, "_")
-		if len(parts) == 3 {
-			id = strings.Join(parts[0:2], "_")
-		}
-		for _, dev := range devices {
-			if dev.IDStr != id {
-				continue
-			}
-			newDevices := mappers.DeviceToAlice(dev)
-			for _, newDev := range newDevices {
-				if newDev.ID != reqDev.ID {
-					continue
-				}
-				aliceDevices.Devices = append(aliceDevices.Devices, newDev)
-			}
-			break
+		if mappers.IsHidden(settings, reqDev.ID) {
+			aliceDevices.Devices = append(aliceDevices.Devices, alice.Device{
+				ID:           reqDev.ID,
+				ErrorCode:    alice.ErrorCodeDeviceNotFound,
+				ErrorMessage: fmt.Sprintf(userMessages(devices).DeviceNotFound, reqDev.ID),
+			})
+			continue
 		}
+		aliceDevices.Devices = append(aliceDevices.Devices, queryDevice(devices, reqDev.ID))
 	}
 	if err := json.NewEncoder(w).Encode(alice.Response{
 		RequestID: r.Header.Get(xRequestID),
@@ -55,3 +53,40 @@ func (s *service) Query(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
+
+func queryDevice(devices []*device_provider.Device, reqID string) alice.Device {
+	id, err := mappers.ParseDeviceID(reqID)
+	if err == nil {
+		if dev := mappers.FindDevice(devices, id); dev != nil {
+			if !dev.Connected {
+				return alice.Device{
+					ID:           reqID,
+					ErrorCode:    alice.ErrorCodeDeviceUnreachable,
+					ErrorMessage: i18n.For(dev.House.Language).DeviceOffline,
+				}
+			}
+			for _, newDev := range mappers.DeviceToAlice(dev) {
+				if newDev.ID == reqID {
+					return newDev
+				}
+			}
+			// датчик, который Алиса запомнила до переключения в объединенное представление
+			if sensor, exists := mappers.SensorToAlice(dev, id.Sensor); exists {
+				return sensor
+			}
+		}
+	}
+	return alice.Device{
+		ID:           reqID,
+		ErrorCode:    alice.ErrorCodeDeviceNotFound,
+		ErrorMessage: fmt.Sprintf(userMessages(devices).DeviceNotFound, reqID),
+	}
+}
+
+// userMessages каталог для ответов без конкретного устройства, берется из первой привязки пользователя
+func userMessages(devices []*device_provider.Device) *i18n.Messages {
+	if len(devices) == 0 {
+		return i18n.For(i18n.DefaultLanguage)
+	}
+	return i18n.For(devices[0].House.Language)
+}
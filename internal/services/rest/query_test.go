@@ -0,0 +1,50 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"sstcloud-alice-gateway/internal/models/alice"
+	"sstcloud-alice-gateway/pkg/middleware/user"
+)
+
+func doQuery(t *testing.T, s *service, ids ...string) []alice.Device {
+	t.Helper()
+	payload := `{"devices":[{"id":"` + strings.Join(ids, `"},{"id":"`) + `"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/v1.0/user/devices/query", strings.NewReader(payload))
+	req.Header.Set("X-User-Id", "user")
+	rec := httptest.NewRecorder()
+	user.Middleware(http.HandlerFunc(s.Query)).ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body.String())
+	}
+	var response struct {
+		Payload alice.Devices `json:"payload"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(response.Payload.Devices) != len(ids) {
+		t.Fatalf("expected %d devices, got %d", len(ids), len(response.Payload.Devices))
+	}
+	return response.Payload.Devices
+}
+
+func TestQueryHiddenDevice(t *testing.T) {
+	s := newTestService(t, testDevice(&fakeProvider{}, true))
+	for _, device := range doQuery(t, s, "1_2", "1_2_air") {
+		if device.ErrorCode != "" {
+			t.Fatalf("%s: unexpected error %s", device.ID, device.ErrorCode)
+		}
+	}
+	// датчики скрываются вместе с термостатом
+	hideDevice(t, s, "1_2")
+	for _, device := range doQuery(t, s, "1_2", "1_2_air") {
+		if device.ErrorCode != alice.ErrorCodeDeviceNotFound {
+			t.Fatalf("%s: expected %s, got %q", device.ID, alice.ErrorCodeDeviceNotFound, device.ErrorCode)
+		}
+	}
+}
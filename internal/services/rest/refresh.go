@@ -0,0 +1,27 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+
+	"sstcloud-alice-gateway/pkg/middleware/user"
+)
+
+func (s *service) RefreshHouse(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
+	houseID, err := strconv.Atoi(chi.URLParam(r, "house_id"))
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed parse house id")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.deviceProvider.Refresh(user.User(ctx), houseID) {
+		http.Error(w, "house not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"sstcloud-alice-gateway/internal/device_provider"
+	"sstcloud-alice-gateway/internal/notifier"
+	"sstcloud-alice-gateway/internal/notifier/broadcast"
 	"sstcloud-alice-gateway/internal/storage"
 	"sstcloud-alice-gateway/pkg/middleware/user"
 )
@@ -22,15 +24,36 @@ type service struct {
 	srv            *http.Server
 	storage        storage.Storage
 	deviceProvider DeviceProvider
+	notifierStats  NotifierStats
+	subscriber     Subscriber
+	// streams закрывается при остановке сервера, иначе открытые SSE потоки не дадут ему завершиться
+	streams     context.Context
+	stopStreams context.CancelFunc
 }
 
 type DeviceProvider interface {
 	Devices(userID string) []*device_provider.Device
+	Refresh(userID string, houseID int) bool
+	// ReloadLinks применяет изменения привязок и сообщает Алисе об измененных устройствах
+	ReloadLinks()
+	// NotifyDevicesListChanged сообщает Алисе, что устройства пользователя нужно перечитать
+	NotifyDevicesListChanged(ctx context.Context, userID string) error
+}
+
+type NotifierStats interface {
+	Stats() notifier.QueueStats
+}
+
+type Subscriber interface {
+	Subscribe(userID string) (<-chan broadcast.Event, func())
 }
 
 const xRequestID = "X-Request-Id"
 
-func New(ctx context.Context, config Config, log zerolog.Logger, storage storage.Storage, deviceProvider DeviceProvider) (*service, error) {
+// shutdownTimeout сколько ждать завершения запросов при остановке, потом соединения закрываются принудительно
+const shutdownTimeout = 10 * time.Second
+
+func New(ctx context.Context, config Config, log zerolog.Logger, storage storage.Storage, deviceProvider DeviceProvider, notifierStats NotifierStats, subscriber Subscriber) (*service, error) {
 	r := chi.NewRouter()
 	r.Use(
 		hlog.NewHandler(log),
@@ -46,9 +69,13 @@ func New(ctx context.Context, config Config, log zerolog.Logger, storage storage
 	service := service{
 		config:         config,
 		deviceProvider: deviceProvider,
+		notifierStats:  notifierStats,
+		subscriber:     subscriber,
 		srv:            &http.Server{Addr: config.Address, Handler: r},
 		storage:        storage,
 	}
+	service.streams, service.stopStreams = context.WithCancel(context.Background())
+	service.srv.RegisterOnShutdown(service.stopStreams)
 
 	r.Route("/v1.0", func(r chi.Router) {
 		r.Head("/", service.Health)
@@ -58,10 +85,34 @@ func New(ctx context.Context, config Config, log zerolog.Logger, storage storage
 			r.Post("/action", service.Action)
 		})
 	})
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Post("/houses/{house_id}/refresh", service.RefreshHouse)
+		r.Get("/notifier/stats", service.NotifierStats)
+		r.Get("/devices/stream", service.Stream)
+		r.Get("/devices/{device_id}/history", service.History)
+		r.Get("/links", service.Links)
+		r.Put("/links/{link_id}/language", service.SetLinkLanguage)
+		r.Put("/links/{link_id}/layout", service.SetLinkLayout)
+		r.Get("/devices/settings", service.DeviceSettings)
+		r.Put("/devices/{device_id}/settings", service.SaveDeviceSetting)
+		r.Delete("/devices/{device_id}/settings", service.DeleteDeviceSetting)
+		r.Get("/commands", service.Commands)
+		r.Route("/webhooks", func(r chi.Router) {
+			r.Get("/", service.Webhooks)
+			r.Post("/", service.AddWebhook)
+			r.Delete("/{webhook_id}", service.DeleteWebhook)
+			r.Get("/{webhook_id}/deliveries", service.WebhookDeliveries)
+		})
+	})
 
 	return &service, nil
 }
 
+// Handler обработчик запросов без запуска сервера, для httptest
+func (s *service) Handler() http.Handler {
+	return s.srv.Handler
+}
+
 func (s *service) Run(ctx context.Context, ready func()) error {
 	logger := log.Ctx(ctx)
 	logger.Info().Str("address", s.srv.Addr).Msg("Start listening")
@@ -82,9 +133,14 @@ func (s *service) Run(ctx context.Context, ready func()) error {
 
 func (s *service) Shutdown(ctx context.Context) error {
 	logger := log.Ctx(ctx)
+	ctx, cancel := context.WithTimeout(ctx, shutdownTimeout)
+	defer cancel()
 
 	if err := s.srv.Shutdown(ctx); err != nil {
 		logger.Error().Err(err).Msg("Failed shutdown")
+		if err := s.srv.Close(); err != nil {
+			logger.Error().Err(err).Msg("Failed close server")
+		}
 		return err
 	}
 
@@ -0,0 +1,20 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+)
+
+func (s *service) NotifierStats(w http.ResponseWriter, r *http.Request) {
+	logger := log.Ctx(r.Context())
+	if s.notifierStats == nil {
+		http.Error(w, "notifier queue is disabled", http.StatusNotFound)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(s.notifierStats.Stats()); err != nil {
+		logger.Error().Err(err).Msg("Failed marshal response")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,106 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"sstcloud-alice-gateway/internal/device_provider"
+	"sstcloud-alice-gateway/internal/mappers"
+	"sstcloud-alice-gateway/internal/models/alice"
+	"sstcloud-alice-gateway/pkg/middleware/user"
+)
+
+const (
+	streamKeepAlive = time.Second * 30
+
+	streamEventSnapshot = "snapshot"
+	streamEventUpdate   = "update"
+)
+
+// Stream отдает поток Server-Sent Events: сначала снимок всех устройств пользователя,
+// затем изменившиеся устройства по мере их опроса. При изменении списка устройств снимок повторяется.
+func (s *service) Stream(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	userID := user.User(ctx)
+	events, unsubscribe := s.subscriber.Subscribe(userID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(event string, devices []*device_provider.Device) bool {
+		payload := alice.Devices{
+			UserID:  userID,
+			Devices: make([]alice.Device, 0, len(devices)),
+		}
+		for _, d := range devices {
+			payload.Devices = append(payload.Devices, mappers.DeviceToAlice(d)...)
+		}
+		// настройки читаются на каждое событие, чтобы поток совпадал с discovery
+		settings, err := s.storage.DeviceSettings(ctx, userID)
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed find device settings")
+			return false
+		}
+		payload.Devices = mappers.ApplySettings(payload.Devices, settings)
+		blob, err := json.Marshal(payload)
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed marshal event")
+			return false
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, blob); err != nil {
+			logger.Debug().Err(err).Msg("Stream closed")
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+
+	if !send(streamEventSnapshot, s.deviceProvider.Devices(userID)) {
+		return
+	}
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.streams.Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if event.ListChanged {
+				if !send(streamEventSnapshot, s.deviceProvider.Devices(userID)) {
+					return
+				}
+				continue
+			}
+			devices := make([]*device_provider.Device, 0, len(event.Changes))
+			for _, change := range event.Changes {
+				devices = append(devices, change.Device)
+			}
+			if !send(streamEventUpdate, devices) {
+				return
+			}
+		}
+	}
+}
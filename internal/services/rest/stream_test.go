@@ -0,0 +1,124 @@
+package rest
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"sstcloud-alice-gateway/internal/models/alice"
+	storageModels "sstcloud-alice-gateway/internal/models/storage"
+	"sstcloud-alice-gateway/internal/notifier/broadcast"
+	"sstcloud-alice-gateway/internal/storage/memory"
+	"sstcloud-alice-gateway/pkg/middleware/user"
+)
+
+func TestShutdownClosesStreams(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	ctx := context.Background()
+	store := memory.New("")
+	if err := store.Connect(ctx); err != nil {
+		t.Fatal(err)
+	}
+	s, err := New(ctx, Config{Address: address}, zerolog.Nop(), store, &fakeDevices{}, nil, broadcast.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ready := make(chan struct{})
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- s.Run(ctx, func() { close(ready) })
+	}()
+	<-ready
+
+	var resp *http.Response
+	for i := 0; i < 50; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "http://"+address+"/api/v1/devices/stream", nil)
+		req.Header.Set("X-User-Id", "user")
+		if resp, err = http.DefaultClient.Do(req); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, "event: snapshot") {
+		t.Fatalf("expected snapshot event, got %q (%v)", line, err)
+	}
+
+	// поток открыт, остановка не должна его ждать
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- s.Shutdown(ctx)
+	}()
+	select {
+	case err := <-shutdown:
+		if err != nil {
+			t.Fatalf("shutdown: %v", err)
+		}
+	case <-time.After(shutdownTimeout / 2):
+		t.Fatal("shutdown is blocked by open stream")
+	}
+	if err := <-stopped; err != nil {
+		t.Fatalf("run: %v", err)
+	}
+}
+
+func TestStreamAppliesSettings(t *testing.T) {
+	s := newTestService(t, testDevice(&fakeProvider{}, true))
+	s.subscriber = broadcast.New()
+	s.streams = context.Background()
+	for _, setting := range []*storageModels.DeviceSetting{
+		{UserID: "user", DeviceID: "1_2", Name: "Спальня"},
+		{UserID: "user", DeviceID: "1_2_air", Hidden: true},
+	} {
+		if err := s.storage.SaveDeviceSetting(context.Background(), setting); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// запрос уже отменен: поток отдает снимок и сразу завершается
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/devices/stream", nil).WithContext(ctx)
+	req.Header.Set("X-User-Id", "user")
+	rec := httptest.NewRecorder()
+	user.Middleware(http.HandlerFunc(s.Stream)).ServeHTTP(rec, req)
+
+	var data string
+	for _, line := range strings.Split(rec.Body.String(), "\n") {
+		if strings.HasPrefix(line, "data: ") {
+			data = strings.TrimPrefix(line, "data: ")
+			break
+		}
+	}
+	var snapshot alice.Devices
+	if err := json.Unmarshal([]byte(data), &snapshot); err != nil {
+		t.Fatalf("decode snapshot %q: %v", data, err)
+	}
+	names := map[string]string{}
+	for _, device := range snapshot.Devices {
+		names[device.ID] = device.Name
+	}
+	if _, hidden := names["1_2_air"]; hidden {
+		t.Fatalf("hidden sensor is streamed: %v", names)
+	}
+	if names["1_2"] != "Спальня" || !strings.HasPrefix(names["1_2_floor"], "Спальня") {
+		t.Fatalf("settings are not applied: %v", names)
+	}
+}
@@ -0,0 +1,165 @@
+package rest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+
+	storageModels "sstcloud-alice-gateway/internal/models/storage"
+	webhookNotifier "sstcloud-alice-gateway/internal/notifier/webhook"
+	storagePkg "sstcloud-alice-gateway/internal/storage"
+	"sstcloud-alice-gateway/pkg/middleware/user"
+)
+
+const (
+	webhookSecretLen          = 32
+	defaultWebhookDeliveries  = 50
+	maxWebhookDeliveriesLimit = 500
+)
+
+type webhookRequest struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret"`
+}
+
+type webhookResponse struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type webhookDeliveryResponse struct {
+	ID         string    `json:"id"`
+	Event      string    `json:"event"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code"`
+	Error      string    `json:"error,omitempty"`
+	Time       time.Time `json:"time"`
+}
+
+func (s *service) Webhooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
+	webhooks, err := s.storage.Webhooks(ctx, user.User(ctx))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	result := make([]webhookResponse, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		result = append(result, webhookResponse{
+			ID:        webhook.ID,
+			URL:       webhook.URL,
+			CreatedAt: webhook.CreatedAt,
+		})
+	}
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		logger.Error().Err(err).Msg("Failed marshal response")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *service) AddWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
+	var req webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error().Err(err).Msg("Failed unmarshal data")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := webhookNotifier.ValidateURL(ctx, req.URL, s.config.WebhookAllowPrivateAddresses); err != nil {
+		logger.Warn().Err(err).Str("url", req.URL).Msg("Invalid webhook url")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Secret == "" {
+		secret := make([]byte, webhookSecretLen)
+		if _, err := rand.Read(secret); err != nil {
+			logger.Error().Err(err).Msg("Failed generate secret")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		req.Secret = hex.EncodeToString(secret)
+	}
+	webhook := storageModels.Webhook{
+		UserID: user.User(ctx),
+		URL:    req.URL,
+		Secret: req.Secret,
+	}
+	if err := s.storage.AddWebhook(ctx, &webhook); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(webhookResponse{
+		ID:        webhook.ID,
+		URL:       webhook.URL,
+		Secret:    webhook.Secret,
+		CreatedAt: webhook.CreatedAt,
+	}); err != nil {
+		logger.Error().Err(err).Msg("Failed marshal response")
+	}
+}
+
+func (s *service) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if err := s.storage.DeleteWebhook(ctx, user.User(ctx), chi.URLParam(r, "webhook_id")); err != nil {
+		if errors.Is(err, storagePkg.ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *service) WebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
+	limit := defaultWebhookDeliveries
+	if v := r.URL.Query().Get("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+	if limit > maxWebhookDeliveriesLimit {
+		limit = maxWebhookDeliveriesLimit
+	}
+	deliveries, err := s.storage.WebhookDeliveries(ctx, user.User(ctx), chi.URLParam(r, "webhook_id"), limit)
+	if err != nil {
+		if errors.Is(err, storagePkg.ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	result := make([]webhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		result = append(result, webhookDeliveryResponse{
+			ID:         delivery.ID,
+			Event:      delivery.Event,
+			Attempt:    delivery.Attempt,
+			StatusCode: delivery.StatusCode,
+			Error:      delivery.Error,
+			Time:       delivery.Time,
+		})
+	}
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		logger.Error().Err(err).Msg("Failed marshal response")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,45 @@
+// Package backend выбирает реализацию хранилища по схеме DB_CONNECTION_STRING
+package backend
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"sstcloud-alice-gateway/internal/storage"
+	"sstcloud-alice-gateway/internal/storage/memory"
+	"sstcloud-alice-gateway/internal/storage/sql"
+)
+
+const (
+	schemeMemory = "memory"
+	schemeFile   = "file"
+)
+
+type Storage interface {
+	storage.Storage
+	Connect(ctx context.Context) error
+	Disconnect(ctx context.Context) error
+	Migrate(ctx context.Context) error
+}
+
+// New memory:// - пустое хранилище в памяти, file:///path/links.yaml - хранилище в памяти с привязками из файла,
+// остальные схемы - драйверы sql хранилища
+func New(config sql.Config) (Storage, error) {
+	parsed, err := url.Parse(config.ConnectionString)
+	if err != nil {
+		return nil, err
+	}
+	switch parsed.Scheme {
+	case schemeMemory:
+		return memory.New(""), nil
+	case schemeFile:
+		// file://links.yaml и file:links.yaml дают пустой путь и молча запустили бы пустое хранилище
+		if parsed.Path == "" || parsed.Host != "" || parsed.Opaque != "" {
+			return nil, fmt.Errorf("invalid file connection string %q, expected file:///path/to/links.yaml", config.ConnectionString)
+		}
+		return memory.New(parsed.Path), nil
+	default:
+		return sql.New(config), nil
+	}
+}
@@ -0,0 +1,57 @@
+package backend
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sstcloud-alice-gateway/internal/storage/sql"
+)
+
+func connect(t *testing.T, connectionString string) (Storage, error) {
+	t.Helper()
+	s, err := New(sql.Config{ConnectionString: connectionString})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, s.Connect(context.Background())
+}
+
+func TestNew(t *testing.T) {
+	ctx := context.Background()
+	s, err := connect(t, "memory://")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if links, _ := s.Links(ctx); len(links) != 0 {
+		t.Fatalf("expected empty storage, got %d links", len(links))
+	}
+
+	path := filepath.Join(t.TempDir(), "links.yaml")
+	if err := os.WriteFile(path, []byte("links:\n  - user_id: user\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s, err = connect(t, "file://"+path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if links, _ := s.Links(ctx); len(links) != 1 {
+		t.Fatalf("expected seeded link, got %d links", len(links))
+	}
+
+	// путь файла обязателен и должен быть абсолютным
+	for _, connectionString := range []string{"file://", "file://links.yaml", "file:links.yaml", "file://host/links.yaml"} {
+		if _, err := New(sql.Config{ConnectionString: connectionString}); err == nil {
+			t.Fatalf("expected error for %q", connectionString)
+		}
+	}
+
+	// остальные схемы обрабатывает sql хранилище
+	if _, err := connect(t, "mysql://localhost/gateway"); err == nil {
+		t.Fatal("expected sql storage error for unsupported driver")
+	}
+	if _, err := New(sql.Config{ConnectionString: "postgres://%zz"}); err == nil {
+		t.Fatal("expected error for invalid connection string")
+	}
+}
@@ -3,13 +3,47 @@ package storage
 import (
 	"context"
 	"errors"
+	"time"
 
 	"sstcloud-alice-gateway/internal/models/storage"
 )
 
-var ErrInvalidState = errors.New("invalid state")
+var (
+	ErrInvalidState = errors.New("invalid state")
+	ErrNotFound     = errors.New("not found")
+)
 
 type Storage interface {
 	Links(ctx context.Context) ([]*storage.Link, error)
+	UserLinks(ctx context.Context, userID string) ([]*storage.Link, error)
+	AddLink(ctx context.Context, link *storage.Link) error
+	DeleteLink(ctx context.Context, linkID string) error
+	SetLinkLanguage(ctx context.Context, userID, linkID, language string) error
+	SetLinkLayout(ctx context.Context, userID, linkID, layout string) error
 	Log(ctx context.Context, linkID string, level storage.LogLevel, msg string)
+	Notifications(ctx context.Context) ([]*storage.Notification, error)
+	SaveNotification(ctx context.Context, notification *storage.Notification) error
+	DeleteNotification(ctx context.Context, userID string) error
+	Webhooks(ctx context.Context, userID string) ([]*storage.Webhook, error)
+	AddWebhook(ctx context.Context, webhook *storage.Webhook) error
+	DeleteWebhook(ctx context.Context, userID, webhookID string) error
+	AddWebhookDelivery(ctx context.Context, delivery *storage.WebhookDelivery) error
+	WebhookDeliveries(ctx context.Context, userID, webhookID string, limit int) ([]*storage.WebhookDelivery, error)
+	DeleteWebhookDeliveriesBefore(ctx context.Context, before time.Time) (uint, error)
+	AddMeasurements(ctx context.Context, measurements []*storage.Measurement) error
+	Measurements(ctx context.Context, userID, deviceID string, from, to time.Time) ([]*storage.Measurement, error)
+	DeleteMeasurementsBefore(ctx context.Context, before time.Time) (uint, error)
+	AddCommand(ctx context.Context, command *storage.Command) error
+	Commands(ctx context.Context, filter CommandsFilter) ([]*storage.Command, error)
+	DeviceSettings(ctx context.Context, userID string) ([]*storage.DeviceSetting, error)
+	SaveDeviceSetting(ctx context.Context, setting *storage.DeviceSetting) error
+	DeleteDeviceSetting(ctx context.Context, userID, deviceID string) error
+}
+
+type CommandsFilter struct {
+	UserID   string
+	DeviceID string
+	From     time.Time
+	To       time.Time
+	Limit    int
 }
@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	storageModels "sstcloud-alice-gateway/internal/models/storage"
+)
+
+type seed struct {
+	Links []seedLink `yaml:"links"`
+}
+
+type seedLink struct {
+	ID          string                 `yaml:"id"`
+	UserID      string                 `yaml:"user_id"`
+	Provider    string                 `yaml:"provider"`
+	Credentials map[string]interface{} `yaml:"credentials"`
+	Language    string                 `yaml:"language"`
+	Layout      string                 `yaml:"device_layout"`
+}
+
+// loadLinks читает привязки из YAML или JSON файла (JSON - подмножество YAML)
+func loadLinks(path string) ([]*storageModels.Link, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var parsed seed
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	result := make([]*storageModels.Link, 0, len(parsed.Links))
+	ids := make(map[string]struct{}, len(parsed.Links))
+	for i, l := range parsed.Links {
+		if l.UserID == "" {
+			return nil, fmt.Errorf("link #%d: user_id is required", i+1)
+		}
+		credentials := []byte("{}")
+		if len(l.Credentials) > 0 {
+			if credentials, err = json.Marshal(l.Credentials); err != nil {
+				return nil, fmt.Errorf("link #%d: credentials: %w", i+1, err)
+			}
+		}
+		link := &storageModels.Link{
+			ID:          l.ID,
+			UserID:      l.UserID,
+			Provider:    l.Provider,
+			Credentials: string(credentials),
+			Language:    l.Language,
+			Layout:      l.Layout,
+		}
+		if err := link.BeforeInsert(); err != nil {
+			return nil, err
+		}
+		if _, ok := ids[link.ID]; ok {
+			return nil, fmt.Errorf("link #%d: duplicate id %s", i+1, link.ID)
+		}
+		ids[link.ID] = struct{}{}
+		result = append(result, link)
+	}
+	return result, nil
+}
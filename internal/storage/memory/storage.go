@@ -0,0 +1,457 @@
+// Package memory хранилище в памяти для демо и CI: данные не переживают перезапуск,
+// привязки можно заранее загрузить из файла.
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	storageModels "sstcloud-alice-gateway/internal/models/storage"
+	storagePkg "sstcloud-alice-gateway/internal/storage"
+)
+
+type storage struct {
+	seedPath string
+
+	mu                sync.RWMutex
+	links             []*storageModels.Link
+	notifications     []*storageModels.Notification
+	webhooks          []*storageModels.Webhook
+	webhookDeliveries []*storageModels.WebhookDelivery
+	measurements      []*storageModels.Measurement
+	commands          []*storageModels.Command
+	deviceSettings    []*storageModels.DeviceSetting
+}
+
+// New хранилище в памяти, если seedPath не пустой, при подключении из него загружаются привязки
+func New(seedPath string) *storage {
+	return &storage{
+		seedPath: seedPath,
+	}
+}
+
+func (s *storage) Connect(ctx context.Context) error {
+	logger := log.Ctx(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.links = nil
+	s.notifications = nil
+	s.webhooks = nil
+	s.webhookDeliveries = nil
+	s.measurements = nil
+	s.commands = nil
+	s.deviceSettings = nil
+	if s.seedPath == "" {
+		return nil
+	}
+	links, err := loadLinks(s.seedPath)
+	if err != nil {
+		logger.Error().Err(err).Str("path", s.seedPath).Msg("Failed load links")
+		return err
+	}
+	s.links = links
+	logger.Info().Str("path", s.seedPath).Int("links", len(links)).Msg("Links loaded")
+	return nil
+}
+
+func (s *storage) Disconnect(context.Context) error {
+	return nil
+}
+
+// Migrate схемы нет, метод нужен для совместимости с sql хранилищем
+func (s *storage) Migrate(context.Context) error {
+	return nil
+}
+
+func (s *storage) Links(context.Context) ([]*storageModels.Link, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*storageModels.Link, 0, len(s.links))
+	for _, link := range s.links {
+		l := *link
+		result = append(result, &l)
+	}
+	return result, nil
+}
+
+func (s *storage) UserLinks(_ context.Context, userID string) ([]*storageModels.Link, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*storageModels.Link, 0)
+	for _, link := range s.links {
+		if link.UserID == userID {
+			l := *link
+			result = append(result, &l)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+func (s *storage) AddLink(_ context.Context, link *storageModels.Link) error {
+	if err := link.BeforeInsert(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l := *link
+	s.links = append(s.links, &l)
+	return nil
+}
+
+func (s *storage) DeleteLink(_ context.Context, linkID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	found := false
+	s.links = filter(s.links, func(link *storageModels.Link) bool {
+		if link.ID == linkID {
+			found = true
+			return false
+		}
+		return true
+	})
+	if !found {
+		return storagePkg.ErrNotFound
+	}
+	// как ON DELETE SET NULL в sql хранилище: журнал команд остается
+	for _, command := range s.commands {
+		if command.LinkID != nil && *command.LinkID == linkID {
+			command.LinkID = nil
+		}
+	}
+	return nil
+}
+
+func (s *storage) SetLinkLanguage(_ context.Context, userID, linkID, language string) error {
+	return s.updateLink(userID, linkID, func(link *storageModels.Link) {
+		link.Language = language
+	})
+}
+
+func (s *storage) SetLinkLayout(_ context.Context, userID, linkID, layout string) error {
+	return s.updateLink(userID, linkID, func(link *storageModels.Link) {
+		link.Layout = layout
+	})
+}
+
+func (s *storage) updateLink(userID, linkID string, update func(link *storageModels.Link)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, link := range s.links {
+		if link.ID == linkID && link.UserID == userID {
+			update(link)
+			return link.BeforeUpdate()
+		}
+	}
+	return storagePkg.ErrNotFound
+}
+
+// Log логи привязок не хранятся, читать их из хранилища некому
+func (s *storage) Log(ctx context.Context, linkID string, level storageModels.LogLevel, msg string) {
+	logger := log.Ctx(ctx).With().Str("link_id", linkID).Str("level", string(level)).Str("msg", msg).Logger()
+	switch level {
+	case storageModels.Error:
+		logger.Error().Msg("")
+	case storageModels.Info:
+		logger.Debug().Msg("")
+	}
+}
+
+func (s *storage) Notifications(context.Context) ([]*storageModels.Notification, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*storageModels.Notification, 0, len(s.notifications))
+	for _, notification := range s.notifications {
+		n := *notification
+		result = append(result, &n)
+	}
+	return result, nil
+}
+
+func (s *storage) SaveNotification(_ context.Context, notification *storageModels.Notification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, saved := range s.notifications {
+		if saved.UserID != notification.UserID {
+			continue
+		}
+		notification.ID = saved.ID
+		notification.CreatedAt = saved.CreatedAt
+		if err := notification.BeforeUpdate(); err != nil {
+			return err
+		}
+		n := *notification
+		s.notifications[i] = &n
+		return nil
+	}
+	if err := notification.BeforeInsert(); err != nil {
+		return err
+	}
+	n := *notification
+	s.notifications = append(s.notifications, &n)
+	return nil
+}
+
+func (s *storage) DeleteNotification(_ context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifications = filter(s.notifications, func(notification *storageModels.Notification) bool {
+		return notification.UserID != userID
+	})
+	return nil
+}
+
+func (s *storage) Webhooks(_ context.Context, userID string) ([]*storageModels.Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*storageModels.Webhook, 0)
+	for _, webhook := range s.webhooks {
+		if webhook.UserID == userID {
+			w := *webhook
+			result = append(result, &w)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+func (s *storage) AddWebhook(_ context.Context, webhook *storageModels.Webhook) error {
+	if err := webhook.BeforeInsert(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w := *webhook
+	s.webhooks = append(s.webhooks, &w)
+	return nil
+}
+
+func (s *storage) DeleteWebhook(_ context.Context, userID, webhookID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	found := false
+	s.webhooks = filter(s.webhooks, func(webhook *storageModels.Webhook) bool {
+		if webhook.ID == webhookID && webhook.UserID == userID {
+			found = true
+			return false
+		}
+		return true
+	})
+	if !found {
+		return storagePkg.ErrNotFound
+	}
+	s.webhookDeliveries = filter(s.webhookDeliveries, func(delivery *storageModels.WebhookDelivery) bool {
+		return delivery.WebhookID != webhookID
+	})
+	return nil
+}
+
+func (s *storage) AddWebhookDelivery(_ context.Context, delivery *storageModels.WebhookDelivery) error {
+	if err := delivery.BeforeInsert(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := *delivery
+	s.webhookDeliveries = append(s.webhookDeliveries, &d)
+	return nil
+}
+
+func (s *storage) WebhookDeliveries(_ context.Context, userID, webhookID string, limit int) ([]*storageModels.WebhookDelivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	found := false
+	for _, webhook := range s.webhooks {
+		if webhook.ID == webhookID && webhook.UserID == userID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, storagePkg.ErrNotFound
+	}
+	result := make([]*storageModels.WebhookDelivery, 0)
+	for _, delivery := range s.webhookDeliveries {
+		if delivery.WebhookID == webhookID {
+			d := *delivery
+			result = append(result, &d)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Time.After(result[j].Time)
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (s *storage) DeleteWebhookDeliveriesBefore(_ context.Context, before time.Time) (uint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var deleted uint
+	s.webhookDeliveries = filter(s.webhookDeliveries, func(delivery *storageModels.WebhookDelivery) bool {
+		if delivery.Time.Before(before) {
+			deleted++
+			return false
+		}
+		return true
+	})
+	return deleted, nil
+}
+
+func (s *storage) AddMeasurements(_ context.Context, measurements []*storageModels.Measurement) error {
+	for _, m := range measurements {
+		if err := m.BeforeInsert(); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, measurement := range measurements {
+		m := *measurement
+		s.measurements = append(s.measurements, &m)
+	}
+	return nil
+}
+
+func (s *storage) Measurements(_ context.Context, userID, deviceID string, from, to time.Time) ([]*storageModels.Measurement, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*storageModels.Measurement, 0)
+	for _, measurement := range s.measurements {
+		if measurement.UserID == userID && measurement.DeviceID == deviceID &&
+			!measurement.Time.Before(from) && measurement.Time.Before(to) {
+			m := *measurement
+			result = append(result, &m)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Time.Before(result[j].Time)
+	})
+	return result, nil
+}
+
+func (s *storage) DeleteMeasurementsBefore(_ context.Context, before time.Time) (uint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var deleted uint
+	s.measurements = filter(s.measurements, func(measurement *storageModels.Measurement) bool {
+		if measurement.Time.Before(before) {
+			deleted++
+			return false
+		}
+		return true
+	})
+	return deleted, nil
+}
+
+func (s *storage) AddCommand(_ context.Context, command *storageModels.Command) error {
+	if err := command.BeforeInsert(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := *command
+	s.commands = append(s.commands, &c)
+	return nil
+}
+
+func (s *storage) Commands(_ context.Context, f storagePkg.CommandsFilter) ([]*storageModels.Command, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*storageModels.Command, 0)
+	for _, command := range s.commands {
+		if command.UserID != f.UserID ||
+			(f.DeviceID != "" && command.DeviceID != f.DeviceID) ||
+			(!f.From.IsZero() && command.Time.Before(f.From)) ||
+			(!f.To.IsZero() && !command.Time.Before(f.To)) {
+			continue
+		}
+		c := *command
+		result = append(result, &c)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Time.After(result[j].Time)
+	})
+	if f.Limit > 0 && len(result) > f.Limit {
+		result = result[:f.Limit]
+	}
+	return result, nil
+}
+
+func (s *storage) DeviceSettings(_ context.Context, userID string) ([]*storageModels.DeviceSetting, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*storageModels.DeviceSetting, 0)
+	for _, setting := range s.deviceSettings {
+		if setting.UserID == userID {
+			ds := *setting
+			result = append(result, &ds)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].DeviceID < result[j].DeviceID
+	})
+	return result, nil
+}
+
+func (s *storage) SaveDeviceSetting(_ context.Context, setting *storageModels.DeviceSetting) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, saved := range s.deviceSettings {
+		if saved.UserID != setting.UserID || saved.DeviceID != setting.DeviceID {
+			continue
+		}
+		setting.ID = saved.ID
+		setting.CreatedAt = saved.CreatedAt
+		if err := setting.BeforeUpdate(); err != nil {
+			return err
+		}
+		ds := *setting
+		s.deviceSettings[i] = &ds
+		return nil
+	}
+	if err := setting.BeforeInsert(); err != nil {
+		return err
+	}
+	ds := *setting
+	s.deviceSettings = append(s.deviceSettings, &ds)
+	return nil
+}
+
+func (s *storage) DeleteDeviceSetting(_ context.Context, userID, deviceID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	found := false
+	s.deviceSettings = filter(s.deviceSettings, func(setting *storageModels.DeviceSetting) bool {
+		if setting.UserID == userID && setting.DeviceID == deviceID {
+			found = true
+			return false
+		}
+		return true
+	})
+	if !found {
+		return storagePkg.ErrNotFound
+	}
+	return nil
+}
+
+// filter оставляет элементы, для которых keep вернул true, переиспользуя исходный срез
+func filter[T any](items []T, keep func(T) bool) []T {
+	result := items[:0]
+	for _, item := range items {
+		if keep(item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	storagePkg "sstcloud-alice-gateway/internal/storage"
+	"sstcloud-alice-gateway/internal/storage/storagetest"
+)
+
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storagePkg.Storage {
+		s := New("")
+		if err := s.Connect(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		return s
+	})
+}
+
+func writeSeed(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSeed(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"yaml", "links.yaml", `
+links:
+  - id: 6f0c2a1e-5a43-4f4e-9d7b-2b8c1f1d9e01
+    user_id: alice-user
+    credentials:
+      email: user@example.com
+      password: secret
+    language: en
+    device_layout: split
+  - user_id: alice-user
+    provider: sst
+`},
+		{"json", "links.json", `{"links": [
+  {"id": "6f0c2a1e-5a43-4f4e-9d7b-2b8c1f1d9e01", "user_id": "alice-user",
+   "credentials": {"email": "user@example.com", "password": "secret"}, "language": "en", "device_layout": "split"},
+  {"user_id": "alice-user", "provider": "sst"}
+]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := New(writeSeed(t, tt.file, tt.content))
+			if err := s.Connect(ctx); err != nil {
+				t.Fatal(err)
+			}
+			links, err := s.UserLinks(ctx, "alice-user")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(links) != 2 {
+				t.Fatalf("expected 2 links, got %d", len(links))
+			}
+			first := links[0]
+			if first.ID != "6f0c2a1e-5a43-4f4e-9d7b-2b8c1f1d9e01" || first.Language != "en" || first.Layout != "split" {
+				t.Fatalf("unexpected link %+v", first)
+			}
+			if string(first.ProviderCredentials()) != `{"email":"user@example.com","password":"secret"}` {
+				t.Fatalf("unexpected credentials %s", first.ProviderCredentials())
+			}
+			if links[1].ID == "" || links[1].Credentials != "{}" {
+				t.Fatalf("unexpected link %+v", links[1])
+			}
+		})
+	}
+}
+
+func TestSeedErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"invalid", "links: [\n"},
+		{"no user", "links:\n  - provider: sst\n"},
+		{"duplicate id", "links:\n  - id: a\n    user_id: u\n  - id: a\n    user_id: u\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := New(writeSeed(t, "links.yaml", tt.content)).Connect(context.Background()); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+	if err := New(filepath.Join(t.TempDir(), "missing.yaml")).Connect(context.Background()); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
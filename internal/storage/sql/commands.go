@@ -0,0 +1,52 @@
+package sql
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+
+	storageModels "sstcloud-alice-gateway/internal/models/storage"
+	storagePkg "sstcloud-alice-gateway/internal/storage"
+)
+
+func (s *storage) AddCommand(ctx context.Context, command *storageModels.Command) error {
+	logger := log.Ctx(ctx).With().Str("device_id", command.DeviceID).Logger()
+	if err := s.db.WithContext(ctx).Insert(command); err != nil {
+		logger.Error().Err(err).Msg("Failed add command")
+		return err
+	}
+	return nil
+}
+
+func (s *storage) Commands(ctx context.Context, filter storagePkg.CommandsFilter) ([]*storageModels.Command, error) {
+	logger := log.Ctx(ctx)
+	tail := "WHERE user_id = " + s.db.Placeholder(1)
+	args := []interface{}{filter.UserID}
+	if filter.DeviceID != "" {
+		args = append(args, filter.DeviceID)
+		tail += " AND device_id = " + s.db.Placeholder(len(args))
+	}
+	if !filter.From.IsZero() {
+		args = append(args, filter.From.UTC())
+		tail += " AND time >= " + s.db.Placeholder(len(args))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To.UTC())
+		tail += " AND time < " + s.db.Placeholder(len(args))
+	}
+	tail += " ORDER BY time DESC"
+	if filter.Limit > 0 {
+		tail += " LIMIT " + strconv.Itoa(filter.Limit)
+	}
+	rows, err := s.db.WithContext(ctx).SelectAllFrom(storageModels.CommandTable, tail, args...)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed find commands")
+		return nil, err
+	}
+	result := make([]*storageModels.Command, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, r.(*storageModels.Command))
+	}
+	return result, nil
+}
@@ -3,4 +3,6 @@ package sql
 type Config struct {
 	ConnectionString string `env:"DB_CONNECTION_STRING,required"`
 	LogOnlyErrors    bool   `env:"LOG_ONLY_ERROR,default=true"`
+	// AutoMigrate применять встроенные миграции при подключении
+	AutoMigrate bool `env:"DB_AUTO_MIGRATE"`
 }
@@ -0,0 +1,64 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/rs/zerolog/log"
+
+	storageModels "sstcloud-alice-gateway/internal/models/storage"
+	storagePkg "sstcloud-alice-gateway/internal/storage"
+)
+
+func (s *storage) DeviceSettings(ctx context.Context, userID string) ([]*storageModels.DeviceSetting, error) {
+	logger := log.Ctx(ctx)
+	rows, err := s.db.WithContext(ctx).SelectAllFrom(storageModels.DeviceSettingTable, "WHERE user_id = "+s.db.Placeholder(1)+" ORDER BY device_id", userID)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed find device settings")
+		return nil, err
+	}
+	result := make([]*storageModels.DeviceSetting, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, r.(*storageModels.DeviceSetting))
+	}
+	return result, nil
+}
+
+func (s *storage) SaveDeviceSetting(ctx context.Context, setting *storageModels.DeviceSetting) error {
+	logger := log.Ctx(ctx).With().Str("device_id", setting.DeviceID).Logger()
+	db := s.db.WithContext(ctx)
+	var saved storageModels.DeviceSetting
+	err := db.SelectOneTo(&saved, "WHERE user_id = "+s.db.Placeholder(1)+" AND device_id = "+s.db.Placeholder(2), setting.UserID, setting.DeviceID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Error().Err(err).Msg("Failed find device setting")
+			return err
+		}
+		if err := db.Insert(setting); err != nil {
+			logger.Error().Err(err).Msg("Failed add device setting")
+			return err
+		}
+		return nil
+	}
+	setting.ID = saved.ID
+	setting.CreatedAt = saved.CreatedAt
+	if err := db.Update(setting); err != nil {
+		logger.Error().Err(err).Msg("Failed update device setting")
+		return err
+	}
+	return nil
+}
+
+func (s *storage) DeleteDeviceSetting(ctx context.Context, userID, deviceID string) error {
+	logger := log.Ctx(ctx).With().Str("device_id", deviceID).Logger()
+	deleted, err := s.db.WithContext(ctx).DeleteFrom(storageModels.DeviceSettingTable, "WHERE user_id = "+s.db.Placeholder(1)+" AND device_id = "+s.db.Placeholder(2), userID, deviceID)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed delete device setting")
+		return err
+	}
+	if deleted == 0 {
+		return storagePkg.ErrNotFound
+	}
+	return nil
+}
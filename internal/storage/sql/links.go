@@ -0,0 +1,98 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	storageModels "sstcloud-alice-gateway/internal/models/storage"
+	storagePkg "sstcloud-alice-gateway/internal/storage"
+)
+
+func (s *storage) UserLinks(ctx context.Context, userID string) ([]*storageModels.Link, error) {
+	logger := log.Ctx(ctx)
+	rows, err := s.db.WithContext(ctx).SelectAllFrom(storageModels.LinkTable, "WHERE user_id = "+s.db.Placeholder(1)+" ORDER BY created_at", userID)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed find user links")
+		return nil, err
+	}
+	result := make([]*storageModels.Link, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, r.(*storageModels.Link))
+	}
+	return result, nil
+}
+
+func (s *storage) AddLink(ctx context.Context, link *storageModels.Link) error {
+	logger := log.Ctx(ctx).With().Str("user_id", link.UserID).Logger()
+	if err := s.db.WithContext(ctx).Insert(link); err != nil {
+		logger.Error().Err(err).Msg("Failed add link")
+		return err
+	}
+	return nil
+}
+
+func (s *storage) DeleteLink(ctx context.Context, linkID string) error {
+	logger := log.Ctx(ctx).With().Str("link_id", linkID).Logger()
+	if _, err := uuid.Parse(linkID); err != nil {
+		return storagePkg.ErrNotFound
+	}
+	deleted, err := s.db.WithContext(ctx).DeleteFrom(storageModels.LinkTable, "WHERE id = "+s.db.Placeholder(1), linkID)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed delete link")
+		return err
+	}
+	if deleted == 0 {
+		return storagePkg.ErrNotFound
+	}
+	return nil
+}
+
+func (s *storage) SetLinkLanguage(ctx context.Context, userID, linkID, language string) error {
+	logger := log.Ctx(ctx).With().Str("link_id", linkID).Logger()
+	// id в postgres - uuid, запрос с произвольной строкой завершился бы ошибкой, а не пустым результатом
+	if _, err := uuid.Parse(linkID); err != nil {
+		return storagePkg.ErrNotFound
+	}
+	db := s.db.WithContext(ctx)
+	var link storageModels.Link
+	if err := db.SelectOneTo(&link, "WHERE id = "+s.db.Placeholder(1)+" AND user_id = "+s.db.Placeholder(2), linkID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return storagePkg.ErrNotFound
+		}
+		logger.Error().Err(err).Msg("Failed find link")
+		return err
+	}
+	link.Language = language
+	if err := db.UpdateColumns(&link, "language", "updated_at"); err != nil {
+		logger.Error().Err(err).Msg("Failed update link language")
+		return err
+	}
+	return nil
+}
+
+func (s *storage) SetLinkLayout(ctx context.Context, userID, linkID, layout string) error {
+	logger := log.Ctx(ctx).With().Str("link_id", linkID).Logger()
+	// id в postgres - uuid, запрос с произвольной строкой завершился бы ошибкой, а не пустым результатом
+	if _, err := uuid.Parse(linkID); err != nil {
+		return storagePkg.ErrNotFound
+	}
+	db := s.db.WithContext(ctx)
+	var link storageModels.Link
+	if err := db.SelectOneTo(&link, "WHERE id = "+s.db.Placeholder(1)+" AND user_id = "+s.db.Placeholder(2), linkID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return storagePkg.ErrNotFound
+		}
+		logger.Error().Err(err).Msg("Failed find link")
+		return err
+	}
+	link.Layout = layout
+	if err := db.UpdateColumns(&link, "device_layout", "updated_at"); err != nil {
+		logger.Error().Err(err).Msg("Failed update link layout")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package sql
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gopkg.in/reform.v1"
+
+	storageModels "sstcloud-alice-gateway/internal/models/storage"
+)
+
+func (s *storage) AddMeasurements(ctx context.Context, measurements []*storageModels.Measurement) error {
+	logger := log.Ctx(ctx)
+	if len(measurements) == 0 {
+		return nil
+	}
+	structs := make([]reform.Struct, 0, len(measurements))
+	for _, m := range measurements {
+		structs = append(structs, m)
+	}
+	if err := s.db.WithContext(ctx).InsertMulti(structs...); err != nil {
+		logger.Error().Err(err).Msg("Failed add measurements")
+		return err
+	}
+	return nil
+}
+
+func (s *storage) Measurements(ctx context.Context, userID, deviceID string, from, to time.Time) ([]*storageModels.Measurement, error) {
+	logger := log.Ctx(ctx).With().Str("device_id", deviceID).Logger()
+	rows, err := s.db.WithContext(ctx).SelectAllFrom(storageModels.MeasurementTable,
+		"WHERE user_id = "+s.db.Placeholder(1)+" AND device_id = "+s.db.Placeholder(2)+
+			" AND time >= "+s.db.Placeholder(3)+" AND time < "+s.db.Placeholder(4)+" ORDER BY time",
+		userID, deviceID, from.UTC(), to.UTC())
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed find measurements")
+		return nil, err
+	}
+	result := make([]*storageModels.Measurement, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, r.(*storageModels.Measurement))
+	}
+	return result, nil
+}
+
+func (s *storage) DeleteMeasurementsBefore(ctx context.Context, before time.Time) (uint, error) {
+	logger := log.Ctx(ctx)
+	deleted, err := s.db.WithContext(ctx).DeleteFrom(storageModels.MeasurementTable, "WHERE time < "+s.db.Placeholder(1), before.UTC())
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed delete measurements")
+		return 0, err
+	}
+	return deleted, nil
+}
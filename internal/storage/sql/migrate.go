@@ -0,0 +1,136 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	storagePkg "sstcloud-alice-gateway/internal/storage"
+	"sstcloud-alice-gateway/migrations"
+)
+
+// migrationsLockID ключ advisory lock postgres, чтобы несколько реплик не применяли миграции одновременно
+const migrationsLockID = 7218394610
+
+type migration struct {
+	version uint64
+	name    string
+}
+
+// Migrate применяет встроенные миграции диалекта, которые еще не применены.
+// Версия хранится в schema_migrations в том же формате, что и у golang-migrate,
+// поэтому базы, размеченные утилитой migrate, продолжают обновляться с той же версии.
+func (s *storage) Migrate(ctx context.Context) error {
+	logger := log.Ctx(ctx).With().Str("driver", s.driver).Logger()
+	if s.connection == nil {
+		err := storagePkg.ErrInvalidState
+		logger.Error().Err(err).Msg("Invalid state of connection")
+		return err
+	}
+	pending, err := dialectMigrations(s.driver)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed list migrations")
+		return err
+	}
+	conn, err := s.connection.Conn(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed get connection")
+		return err
+	}
+	defer conn.Close()
+	if s.driver == "postgres" {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationsLockID); err != nil {
+			logger.Error().Err(err).Msg("Failed lock migrations")
+			return err
+		}
+		defer func() {
+			if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationsLockID); err != nil {
+				logger.Error().Err(err).Msg("Failed unlock migrations")
+			}
+		}()
+	}
+	if _, err := conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS schema_migrations (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)"); err != nil {
+		logger.Error().Err(err).Msg("Failed create schema_migrations")
+		return err
+	}
+	var (
+		current uint64
+		dirty   bool
+	)
+	if err := conn.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&current, &dirty); err != nil && err != sql.ErrNoRows {
+		logger.Error().Err(err).Msg("Failed read schema version")
+		return err
+	}
+	if dirty {
+		err := fmt.Errorf("database is dirty at version %d, fix it manually", current)
+		logger.Error().Err(err).Msg("Cannot migrate")
+		return err
+	}
+	applied := 0
+	for _, m := range pending {
+		if m.version <= current {
+			continue
+		}
+		if err := s.apply(ctx, conn, m); err != nil {
+			logger.Error().Err(err).Str("migration", m.name).Msg("Failed apply migration")
+			return err
+		}
+		logger.Info().Str("migration", m.name).Msg("Migration applied")
+		applied++
+	}
+	logger.Info().Int("applied", applied).Msg("Database is up to date")
+	return nil
+}
+
+func (s *storage) apply(ctx context.Context, conn *sql.Conn, m migration) error {
+	query, err := fs.ReadFile(migrations.FS, m.name)
+	if err != nil {
+		return err
+	}
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, string(query)); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations"); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, dirty) VALUES ("+s.db.Placeholder(1)+", false)", m.version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// dialectMigrations миграции драйвера по возрастанию версии, имя файла: <версия>_<название>.up.sql
+func dialectMigrations(driver string) ([]migration, error) {
+	names, err := fs.Glob(migrations.FS, driver+"/*.up.sql")
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no migrations for driver %s", driver)
+	}
+	result := make([]migration, 0, len(names))
+	for _, name := range names {
+		prefix, _, _ := strings.Cut(path.Base(name), "_")
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration name %s: %w", name, err)
+		}
+		result = append(result, migration{version: version, name: name})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].version < result[j].version
+	})
+	return result, nil
+}
@@ -0,0 +1,95 @@
+package sql
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func connectSQLite(t *testing.T, autoMigrate bool) *storage {
+	t.Helper()
+	s := New(Config{
+		ConnectionString: "sqlite3://" + filepath.Join(t.TempDir(), "test.db"),
+		AutoMigrate:      autoMigrate,
+	})
+	if err := s.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Disconnect(context.Background()) })
+	return s
+}
+
+func schemaVersion(t *testing.T, s *storage) (version uint64, dirty bool) {
+	t.Helper()
+	if err := s.connection.QueryRow("SELECT version, dirty FROM schema_migrations").Scan(&version, &dirty); err != nil {
+		t.Fatal(err)
+	}
+	return version, dirty
+}
+
+func TestMigrateIsIdempotent(t *testing.T) {
+	s := connectSQLite(t, true)
+	migrations, err := dialectMigrations("sqlite3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest := migrations[len(migrations)-1].version
+	if version, dirty := schemaVersion(t, s); version != latest || dirty {
+		t.Fatalf("expected version %d, got %d (dirty %v)", latest, version, dirty)
+	}
+	if err := s.Migrate(context.Background()); err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if version, _ := schemaVersion(t, s); version != latest {
+		t.Fatalf("version changed to %d", version)
+	}
+	if _, err := s.Links(context.Background()); err != nil {
+		t.Fatalf("schema is not usable: %v", err)
+	}
+}
+
+func TestMigrateRefusesDirtyDatabase(t *testing.T) {
+	s := connectSQLite(t, false)
+	if _, err := s.connection.Exec("CREATE TABLE schema_migrations (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL); INSERT INTO schema_migrations VALUES (1, true)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Migrate(context.Background()); err == nil {
+		t.Fatal("expected error for dirty database")
+	}
+}
+
+func TestDialectMigrationsAreOrdered(t *testing.T) {
+	for _, driver := range []string{"postgres", "sqlite3"} {
+		t.Run(driver, func(t *testing.T) {
+			migrations, err := dialectMigrations(driver)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 1; i < len(migrations); i++ {
+				if migrations[i-1].version >= migrations[i].version {
+					t.Fatalf("%s is not after %s", migrations[i].name, migrations[i-1].name)
+				}
+			}
+		})
+	}
+	if _, err := dialectMigrations("mysql"); err == nil {
+		t.Fatal("expected error for driver without migrations")
+	}
+}
+
+func TestSQLiteIndexes(t *testing.T) {
+	s := connectSQLite(t, true)
+	for _, index := range []string{
+		"user_id_idx",
+		"webhooks_user_id_idx",
+		"webhook_deliveries_webhook_id_time_idx",
+		"measurements_user_id_device_id_time_idx",
+		"measurements_time_idx",
+		"commands_user_id_time_idx",
+	} {
+		var name string
+		if err := s.connection.QueryRow("SELECT name FROM sqlite_master WHERE type = 'index' AND name = ?", index).Scan(&name); err != nil {
+			t.Fatalf("index %s: %v", index, err)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/rs/zerolog/log"
+
+	storageModels "sstcloud-alice-gateway/internal/models/storage"
+)
+
+func (s *storage) Notifications(ctx context.Context) ([]*storageModels.Notification, error) {
+	logger := log.Ctx(ctx)
+	rows, err := s.db.WithContext(ctx).SelectAllFrom(storageModels.NotificationTable, "")
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed find notifications")
+		return nil, err
+	}
+	result := make([]*storageModels.Notification, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, r.(*storageModels.Notification))
+	}
+	return result, nil
+}
+
+func (s *storage) SaveNotification(ctx context.Context, notification *storageModels.Notification) error {
+	logger := log.Ctx(ctx).With().Str("user_id", notification.UserID).Logger()
+	db := s.db.WithContext(ctx)
+	var saved storageModels.Notification
+	if err := db.FindOneTo(&saved, "user_id", notification.UserID); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Error().Err(err).Msg("Failed find notification")
+			return err
+		}
+		if err := db.Insert(notification); err != nil {
+			logger.Error().Err(err).Msg("Failed add notification")
+			return err
+		}
+		return nil
+	}
+	notification.ID = saved.ID
+	notification.CreatedAt = saved.CreatedAt
+	if err := db.Update(notification); err != nil {
+		logger.Error().Err(err).Msg("Failed update notification")
+		return err
+	}
+	return nil
+}
+
+func (s *storage) DeleteNotification(ctx context.Context, userID string) error {
+	logger := log.Ctx(ctx).With().Str("user_id", userID).Logger()
+	if _, err := s.db.WithContext(ctx).DeleteFrom(storageModels.NotificationTable, "WHERE user_id = "+s.db.Placeholder(1), userID); err != nil {
+		logger.Error().Err(err).Msg("Failed delete notification")
+		return err
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"net/url"
+	"strings"
 	"time"
 
 	_ "github.com/lib/pq"
@@ -55,7 +56,7 @@ func (s *storage) Connect(ctx context.Context) error {
 	// sqlite3 не работает, если в начале connectionString - схема.
 	// а без нее не работает postgres(не парсит аргументы)
 	if s.driver == "sqlite3" {
-		connectionString = s.config.ConnectionString[len(s.driver)+3:]
+		connectionString = sqliteConnectionString(s.config.ConnectionString[len(s.driver)+3:])
 	}
 	sqlDB, err := sql.Open(s.driver, connectionString)
 	if err != nil {
@@ -67,9 +68,23 @@ func (s *storage) Connect(ctx context.Context) error {
 	t := reform.NewDB(sqlDB, dialects.ForDriver(s.driver), reform.NewPrintfLogger(logger.Printf))
 	s.db = t.Querier
 	s.reformDB = t
+	if s.config.AutoMigrate {
+		return s.Migrate(ctx)
+	}
 	return nil
 }
 
+// sqliteConnectionString включает внешние ключи, без них sqlite не удаляет каскадно логи и команды привязки
+func sqliteConnectionString(dsn string) string {
+	if strings.Contains(dsn, "_foreign_keys=") || strings.Contains(dsn, "_fk=") {
+		return dsn
+	}
+	if strings.Contains(dsn, "?") {
+		return dsn + "&_foreign_keys=1"
+	}
+	return dsn + "?_foreign_keys=1"
+}
+
 func (s *storage) Disconnect(ctx context.Context) error {
 	logger := log.Ctx(ctx)
 	if s.connection == nil {
@@ -0,0 +1,48 @@
+package sql
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	storagePkg "sstcloud-alice-gateway/internal/storage"
+	"sstcloud-alice-gateway/internal/storage/storagetest"
+)
+
+func TestSQLiteStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storagePkg.Storage {
+		return connectSQLite(t, true)
+	})
+}
+
+// TestPostgresStorage запускается при заданной TEST_POSTGRES_CONNECTION_STRING, база должна быть пустой или размеченной шлюзом
+func TestPostgresStorage(t *testing.T) {
+	connectionString := os.Getenv("TEST_POSTGRES_CONNECTION_STRING")
+	if connectionString == "" {
+		t.Skip("TEST_POSTGRES_CONNECTION_STRING is not set")
+	}
+	storagetest.Run(t, func(t *testing.T) storagePkg.Storage {
+		s := New(Config{ConnectionString: connectionString, AutoMigrate: true})
+		if err := s.Connect(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { s.Disconnect(context.Background()) })
+		return s
+	})
+}
+
+func TestSQLiteConnectionString(t *testing.T) {
+	tests := []struct {
+		dsn      string
+		expected string
+	}{
+		{"/data/gateway.db", "/data/gateway.db?_foreign_keys=1"},
+		{"/data/gateway.db?_busy_timeout=5000", "/data/gateway.db?_busy_timeout=5000&_foreign_keys=1"},
+		{"/data/gateway.db?_foreign_keys=0", "/data/gateway.db?_foreign_keys=0"},
+	}
+	for _, tt := range tests {
+		if actual := sqliteConnectionString(tt.dsn); actual != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.dsn, tt.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	storageModels "sstcloud-alice-gateway/internal/models/storage"
+	storagePkg "sstcloud-alice-gateway/internal/storage"
+)
+
+func (s *storage) Webhooks(ctx context.Context, userID string) ([]*storageModels.Webhook, error) {
+	logger := log.Ctx(ctx)
+	rows, err := s.db.WithContext(ctx).SelectAllFrom(storageModels.WebhookTable, "WHERE user_id = "+s.db.Placeholder(1)+" ORDER BY created_at", userID)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed find webhooks")
+		return nil, err
+	}
+	result := make([]*storageModels.Webhook, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, r.(*storageModels.Webhook))
+	}
+	return result, nil
+}
+
+func (s *storage) AddWebhook(ctx context.Context, webhook *storageModels.Webhook) error {
+	logger := log.Ctx(ctx)
+	if err := s.db.WithContext(ctx).Insert(webhook); err != nil {
+		logger.Error().Err(err).Msg("Failed add webhook")
+		return err
+	}
+	return nil
+}
+
+func (s *storage) DeleteWebhook(ctx context.Context, userID, webhookID string) error {
+	logger := log.Ctx(ctx).With().Str("webhook_id", webhookID).Logger()
+	if _, err := uuid.Parse(webhookID); err != nil {
+		return storagePkg.ErrNotFound
+	}
+	deleted, err := s.db.WithContext(ctx).DeleteFrom(storageModels.WebhookTable, "WHERE id = "+s.db.Placeholder(1)+" AND user_id = "+s.db.Placeholder(2), webhookID, userID)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed delete webhook")
+		return err
+	}
+	if deleted == 0 {
+		return storagePkg.ErrNotFound
+	}
+	return nil
+}
+
+func (s *storage) AddWebhookDelivery(ctx context.Context, delivery *storageModels.WebhookDelivery) error {
+	logger := log.Ctx(ctx).With().Str("webhook_id", delivery.WebhookID).Logger()
+	if err := s.db.WithContext(ctx).Insert(delivery); err != nil {
+		logger.Error().Err(err).Msg("Failed add webhook delivery")
+		return err
+	}
+	return nil
+}
+
+func (s *storage) WebhookDeliveries(ctx context.Context, userID, webhookID string, limit int) ([]*storageModels.WebhookDelivery, error) {
+	logger := log.Ctx(ctx).With().Str("webhook_id", webhookID).Logger()
+	db := s.db.WithContext(ctx)
+	// id в postgres - uuid, запрос с произвольной строкой завершился бы ошибкой, а не пустым результатом
+	if _, err := uuid.Parse(webhookID); err != nil {
+		return nil, storagePkg.ErrNotFound
+	}
+	var webhook storageModels.Webhook
+	if err := db.FindOneTo(&webhook, "id", webhookID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storagePkg.ErrNotFound
+		}
+		logger.Error().Err(err).Msg("Failed find webhook")
+		return nil, err
+	}
+	if webhook.UserID != userID {
+		return nil, storagePkg.ErrNotFound
+	}
+	rows, err := db.SelectAllFrom(storageModels.WebhookDeliveryTable, "WHERE webhook_id = "+s.db.Placeholder(1)+" ORDER BY time DESC LIMIT "+strconv.Itoa(limit), webhookID)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed find webhook deliveries")
+		return nil, err
+	}
+	result := make([]*storageModels.WebhookDelivery, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, r.(*storageModels.WebhookDelivery))
+	}
+	return result, nil
+}
+
+func (s *storage) DeleteWebhookDeliveriesBefore(ctx context.Context, before time.Time) (uint, error) {
+	logger := log.Ctx(ctx)
+	deleted, err := s.db.WithContext(ctx).DeleteFrom(storageModels.WebhookDeliveryTable, "WHERE time < "+s.db.Placeholder(1), before.UTC())
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed delete webhook deliveries")
+		return 0, err
+	}
+	return deleted, nil
+}
@@ -0,0 +1,463 @@
+// Package storagetest общий набор тестов для реализаций storage.Storage.
+// Тесты используют собственные идентификаторы пользователей, поэтому могут работать с общей базой.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	storageModels "sstcloud-alice-gateway/internal/models/storage"
+	"sstcloud-alice-gateway/internal/storage"
+)
+
+// Run запускает набор тестов, open вызывается для каждого теста и возвращает готовое хранилище
+func Run(t *testing.T, open func(t *testing.T) storage.Storage) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s storage.Storage)
+	}{
+		{"Links", testLinks},
+		{"DeleteLinkKeepsCommands", testDeleteLinkKeepsCommands},
+		{"Notifications", testNotifications},
+		{"Webhooks", testWebhooks},
+		{"Measurements", testMeasurements},
+		{"Commands", testCommands},
+		{"DeviceSettings", testDeviceSettings},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, open(t))
+		})
+	}
+}
+
+func addLink(t *testing.T, s storage.Storage, userID string) *storageModels.Link {
+	t.Helper()
+	link := &storageModels.Link{
+		UserID:      userID,
+		Provider:    "sst",
+		Credentials: `{"email":"user@example.com","password":"secret"}`,
+	}
+	if err := s.AddLink(context.Background(), link); err != nil {
+		t.Fatal(err)
+	}
+	return link
+}
+
+func testLinks(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	userID := uuid.NewString()
+	first := addLink(t, s, userID)
+	if _, err := uuid.Parse(first.ID); err != nil {
+		t.Fatalf("link id %q is not uuid: %v", first.ID, err)
+	}
+	if first.CreatedAt.IsZero() {
+		t.Fatal("created_at is not set")
+	}
+	second := addLink(t, s, userID)
+	addLink(t, s, uuid.NewString())
+
+	links, err := s.UserLinks(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != 2 || links[0].ID != first.ID || links[1].ID != second.ID {
+		t.Fatalf("unexpected user links %+v", links)
+	}
+	if links[0].Credentials != first.Credentials || links[0].ProviderName() != "sst" {
+		t.Fatalf("link is not stored as is: %+v", links[0])
+	}
+
+	all, err := s.Links(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !containsLink(all, first.ID) || !containsLink(all, second.ID) {
+		t.Fatal("links are missing in Links")
+	}
+
+	if err := s.SetLinkLanguage(ctx, userID, first.ID, "en"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetLinkLayout(ctx, userID, first.ID, "split"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetLinkLanguage(ctx, uuid.NewString(), first.ID, "en"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for foreign user, got %v", err)
+	}
+	if err := s.SetLinkLayout(ctx, userID, uuid.NewString(), "split"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for unknown link, got %v", err)
+	}
+	// в postgres id - uuid, некорректная строка тоже означает несуществующую привязку
+	if err := s.SetLinkLanguage(ctx, userID, "not-a-link", "en"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for malformed link id, got %v", err)
+	}
+	if err := s.SetLinkLayout(ctx, userID, "not-a-link", "split"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for malformed link id, got %v", err)
+	}
+	if err := s.DeleteLink(ctx, "not-a-link"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for malformed link id, got %v", err)
+	}
+	links, err = s.UserLinks(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if links[0].Language != "en" || links[0].Layout != "split" {
+		t.Fatalf("link settings are not saved: %+v", links[0])
+	}
+
+	if err := s.DeleteLink(ctx, second.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteLink(ctx, second.ID); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound on second delete, got %v", err)
+	}
+	links, err = s.UserLinks(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != 1 {
+		t.Fatalf("expected 1 link after delete, got %d", len(links))
+	}
+}
+
+func containsLink(links []*storageModels.Link, id string) bool {
+	for _, link := range links {
+		if link.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func testDeleteLinkKeepsCommands(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	userID := uuid.NewString()
+	link := addLink(t, s, userID)
+	s.Log(ctx, link.ID, storageModels.Error, "failed")
+	if err := s.AddCommand(ctx, &storageModels.Command{
+		UserID:   userID,
+		LinkID:   &link.ID,
+		DeviceID: "1",
+		Command:  storageModels.CommandPower,
+		Origin:   storageModels.CommandOriginAlice,
+		Status:   storageModels.CommandStatusSuccess,
+		Time:     time.Now().UTC(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteLink(ctx, link.ID); err != nil {
+		t.Fatal(err)
+	}
+	commands, err := s.Commands(ctx, storage.CommandsFilter{UserID: userID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// журнал команд переживает удаление привязки
+	if len(commands) != 1 || commands[0].LinkID != nil {
+		t.Fatalf("expected command without link, got %+v", commands)
+	}
+}
+
+func findNotification(t *testing.T, s storage.Storage, userID string) *storageModels.Notification {
+	t.Helper()
+	notifications, err := s.Notifications(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range notifications {
+		if n.UserID == userID {
+			return n
+		}
+	}
+	return nil
+}
+
+func testNotifications(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	userID := uuid.NewString()
+	if err := s.SaveNotification(ctx, &storageModels.Notification{
+		UserID:        userID,
+		Payload:       `{"devices":[]}`,
+		NextAttemptAt: time.Now().UTC(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	saved := findNotification(t, s, userID)
+	if saved == nil || saved.Payload != `{"devices":[]}` || saved.Discovery {
+		t.Fatalf("unexpected notification %+v", saved)
+	}
+	// одно уведомление на пользователя, повторное сохранение заменяет его
+	if err := s.SaveNotification(ctx, &storageModels.Notification{
+		UserID:        userID,
+		Payload:       `{}`,
+		Discovery:     true,
+		Attempts:      2,
+		LastError:     "timeout",
+		NextAttemptAt: time.Now().UTC().Add(time.Minute),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	updated := findNotification(t, s, userID)
+	if updated == nil || updated.ID != saved.ID || !updated.Discovery || updated.Attempts != 2 || updated.LastError != "timeout" {
+		t.Fatalf("notification is not updated: %+v", updated)
+	}
+	if err := s.DeleteNotification(ctx, userID); err != nil {
+		t.Fatal(err)
+	}
+	if n := findNotification(t, s, userID); n != nil {
+		t.Fatalf("notification is not deleted: %+v", n)
+	}
+	if err := s.DeleteNotification(ctx, userID); err != nil {
+		t.Fatalf("delete of missing notification: %v", err)
+	}
+}
+
+func testWebhooks(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	userID := uuid.NewString()
+	webhook := &storageModels.Webhook{UserID: userID, URL: "http://example.com/hook", Secret: "secret"}
+	if err := s.AddWebhook(ctx, webhook); err != nil {
+		t.Fatal(err)
+	}
+	webhooks, err := s.Webhooks(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(webhooks) != 1 || webhooks[0].ID != webhook.ID || webhooks[0].URL != webhook.URL {
+		t.Fatalf("unexpected webhooks %+v", webhooks)
+	}
+
+	start := time.Now().UTC()
+	for i := 1; i <= 3; i++ {
+		if err := s.AddWebhookDelivery(ctx, &storageModels.WebhookDelivery{
+			WebhookID:  webhook.ID,
+			Event:      "state",
+			Payload:    "{}",
+			Attempt:    i,
+			StatusCode: 500,
+			Time:       start.Add(time.Duration(i) * time.Second),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deliveries, err := s.WebhookDeliveries(ctx, userID, webhook.ID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 2 || deliveries[0].Attempt != 3 || deliveries[1].Attempt != 2 {
+		t.Fatalf("expected two latest deliveries, got %+v", deliveries)
+	}
+	if _, err := s.WebhookDeliveries(ctx, uuid.NewString(), webhook.ID, 10); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for foreign user, got %v", err)
+	}
+	if _, err := s.WebhookDeliveries(ctx, userID, uuid.NewString(), 10); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for unknown webhook, got %v", err)
+	}
+	if _, err := s.WebhookDeliveries(ctx, userID, "not-a-uuid", 10); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for malformed id, got %v", err)
+	}
+	if err := s.DeleteWebhook(ctx, userID, "not-a-uuid"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for malformed id, got %v", err)
+	}
+
+	// время в прошлом, чтобы удаление старого журнала не задело другие тесты на общей базе
+	old := time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)
+	if err := s.AddWebhookDelivery(ctx, &storageModels.WebhookDelivery{
+		WebhookID: webhook.ID,
+		Event:     "state",
+		Payload:   "{}",
+		Attempt:   1,
+		Time:      old,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	deleted, err := s.DeleteWebhookDeliveriesBefore(ctx, old.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted < 1 {
+		t.Fatalf("expected old delivery to be deleted, got %d", deleted)
+	}
+	deliveries, err = s.WebhookDeliveries(ctx, userID, webhook.ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 3 {
+		t.Fatalf("expected recent deliveries to be kept, got %d", len(deliveries))
+	}
+
+	if err := s.DeleteWebhook(ctx, uuid.NewString(), webhook.ID); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for foreign user, got %v", err)
+	}
+	if err := s.DeleteWebhook(ctx, userID, webhook.ID); err != nil {
+		t.Fatal(err)
+	}
+	webhooks, err = s.Webhooks(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(webhooks) != 0 {
+		t.Fatalf("webhook is not deleted: %+v", webhooks)
+	}
+}
+
+func testMeasurements(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	userID := uuid.NewString()
+	// время в прошлом, чтобы удаление старых измерений не задело другие тесты на общей базе
+	base := time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)
+	measurements := make([]*storageModels.Measurement, 0, 4)
+	for i := 0; i < 3; i++ {
+		measurements = append(measurements, &storageModels.Measurement{
+			UserID:          userID,
+			DeviceID:        "1",
+			Time:            base.Add(time.Duration(i) * time.Hour),
+			DegreesAir:      21.5,
+			DegreesFloor:    25 + float64(i),
+			SetDegreesFloor: 28,
+			Enabled:         true,
+		})
+	}
+	measurements = append(measurements, &storageModels.Measurement{UserID: userID, DeviceID: "2", Time: base})
+	if err := s.AddMeasurements(ctx, measurements); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddMeasurements(ctx, nil); err != nil {
+		t.Fatalf("empty batch: %v", err)
+	}
+
+	result, err := s.Measurements(ctx, userID, "1", base, base.Add(2*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 || !result[0].Time.Equal(base) || !result[1].Time.Equal(base.Add(time.Hour)) {
+		t.Fatalf("unexpected measurements %+v", result)
+	}
+	if result[1].DegreesAir != 21.5 || result[1].DegreesFloor != 26 || !result[1].Enabled {
+		t.Fatalf("measurement is not stored as is: %+v", result[1])
+	}
+	if result[0].ID == result[1].ID {
+		t.Fatal("measurements share id")
+	}
+
+	deleted, err := s.DeleteMeasurementsBefore(ctx, base.Add(90*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted < 3 {
+		t.Fatalf("expected at least 3 deleted measurements, got %d", deleted)
+	}
+	result, err = s.Measurements(ctx, userID, "1", base, base.Add(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 || !result[0].Time.Equal(base.Add(2*time.Hour)) {
+		t.Fatalf("unexpected measurements after delete %+v", result)
+	}
+}
+
+func testCommands(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	userID := uuid.NewString()
+	link := addLink(t, s, userID)
+	base := time.Now().UTC().Truncate(time.Second).Add(-time.Hour)
+	for i, deviceID := range []string{"1", "2", "1"} {
+		if err := s.AddCommand(ctx, &storageModels.Command{
+			UserID:     userID,
+			LinkID:     &link.ID,
+			DeviceID:   deviceID,
+			DeviceName: "Floor",
+			Command:    storageModels.CommandTemperature,
+			OldValue:   "25",
+			NewValue:   "28",
+			Origin:     storageModels.CommandOriginAlice,
+			RequestID:  "request",
+			Status:     storageModels.CommandStatusSuccess,
+			Time:       base.Add(time.Duration(i) * time.Minute),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	commands, err := s.Commands(ctx, storage.CommandsFilter{UserID: userID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commands) != 3 || !commands[0].Time.Equal(base.Add(2*time.Minute)) {
+		t.Fatalf("expected 3 commands, newest first, got %+v", commands)
+	}
+	if commands[0].Command != storageModels.CommandTemperature || commands[0].NewValue != "28" || commands[0].Origin != storageModels.CommandOriginAlice ||
+		commands[0].LinkID == nil || *commands[0].LinkID != link.ID {
+		t.Fatalf("command is not stored as is: %+v", commands[0])
+	}
+
+	tests := []struct {
+		name   string
+		filter storage.CommandsFilter
+		count  int
+	}{
+		{"device", storage.CommandsFilter{UserID: userID, DeviceID: "1"}, 2},
+		{"from", storage.CommandsFilter{UserID: userID, From: base.Add(time.Minute)}, 2},
+		{"to", storage.CommandsFilter{UserID: userID, To: base.Add(time.Minute)}, 1},
+		{"limit", storage.CommandsFilter{UserID: userID, Limit: 1}, 1},
+		{"other user", storage.CommandsFilter{UserID: uuid.NewString()}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commands, err := s.Commands(ctx, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(commands) != tt.count {
+				t.Fatalf("expected %d commands, got %d", tt.count, len(commands))
+			}
+		})
+	}
+}
+
+func testDeviceSettings(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	userID := uuid.NewString()
+	for _, deviceID := range []string{"2", "1"} {
+		if err := s.SaveDeviceSetting(ctx, &storageModels.DeviceSetting{UserID: userID, DeviceID: deviceID, Name: "Floor " + deviceID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	settings, err := s.DeviceSettings(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(settings) != 2 || settings[0].DeviceID != "1" || settings[1].DeviceID != "2" {
+		t.Fatalf("unexpected settings %+v", settings)
+	}
+
+	if err := s.SaveDeviceSetting(ctx, &storageModels.DeviceSetting{UserID: userID, DeviceID: "1", Room: "Kitchen", Hidden: true}); err != nil {
+		t.Fatal(err)
+	}
+	settings, err = s.DeviceSettings(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(settings) != 2 || settings[0].Room != "Kitchen" || !settings[0].Hidden || settings[0].Name != "" {
+		t.Fatalf("setting is not replaced: %+v", settings[0])
+	}
+
+	if err := s.DeleteDeviceSetting(ctx, userID, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteDeviceSetting(ctx, userID, "1"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound on second delete, got %v", err)
+	}
+	settings, err = s.DeviceSettings(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(settings) != 1 || settings[0].DeviceID != "2" {
+		t.Fatalf("unexpected settings after delete %+v", settings)
+	}
+}
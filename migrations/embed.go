@@ -0,0 +1,7 @@
+// Package migrations схемы бд по диалектам: каталог называется так же, как драйвер в DB_CONNECTION_STRING.
+package migrations
+
+import "embed"
+
+//go:embed postgres/*.up.sql sqlite3/*.up.sql
+var FS embed.FS
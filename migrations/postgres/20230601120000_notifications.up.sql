@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS notifications
+(
+    id uuid NOT NULL default uuid_generate_v4(),
+    user_id uuid NOT NULL,
+    payload text NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    next_attempt_at timestamp without time zone NOT NULL DEFAULT now(),
+    last_error text NOT NULL DEFAULT '',
+    created_at timestamp without time zone NOT NULL DEFAULT now(),
+    updated_at timestamp without time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (id),
+    UNIQUE (user_id)
+);
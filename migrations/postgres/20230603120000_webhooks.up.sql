@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS webhooks
+(
+    id uuid NOT NULL default uuid_generate_v4(),
+    user_id uuid NOT NULL,
+    url text NOT NULL,
+    secret text NOT NULL,
+    created_at timestamp without time zone NOT NULL DEFAULT now(),
+    updated_at timestamp without time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS webhooks_user_id_idx
+    ON webhooks USING btree
+        (user_id ASC NULLS LAST);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries
+(
+    id uuid NOT NULL default uuid_generate_v4(),
+    webhook_id uuid NOT NULL,
+    event text NOT NULL,
+    payload text NOT NULL,
+    attempt integer NOT NULL,
+    status_code integer NOT NULL DEFAULT 0,
+    error text NOT NULL DEFAULT '',
+    time timestamp without time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (id),
+    FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_time_idx
+    ON webhook_deliveries USING btree
+        (webhook_id ASC NULLS LAST, time DESC NULLS LAST);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS measurements
+(
+    id uuid NOT NULL default uuid_generate_v4(),
+    user_id uuid NOT NULL,
+    device_id character varying(45) NOT NULL,
+    time timestamp without time zone NOT NULL DEFAULT now(),
+    degrees_air integer NOT NULL,
+    degrees_floor integer NOT NULL,
+    set_degrees_floor integer NOT NULL,
+    enabled boolean NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS measurements_user_id_device_id_time_idx
+    ON measurements USING btree
+        (user_id ASC NULLS LAST, device_id ASC NULLS LAST, time ASC NULLS LAST);
+
+CREATE INDEX IF NOT EXISTS measurements_time_idx
+    ON measurements USING btree
+        (time ASC NULLS LAST);
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS commands
+(
+    id uuid NOT NULL default uuid_generate_v4(),
+    user_id uuid NOT NULL,
+    link_id uuid NOT NULL,
+    device_id character varying(45) NOT NULL,
+    device_name text NOT NULL,
+    command character varying(20) NOT NULL,
+    old_value text NOT NULL,
+    new_value text NOT NULL,
+    origin character varying(20) NOT NULL,
+    request_id text NOT NULL DEFAULT '',
+    status character varying(20) NOT NULL,
+    error text NOT NULL DEFAULT '',
+    time timestamp without time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (id),
+    FOREIGN KEY (link_id) REFERENCES links(id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS commands_user_id_time_idx
+    ON commands USING btree
+        (user_id ASC NULLS LAST, time DESC NULLS LAST);
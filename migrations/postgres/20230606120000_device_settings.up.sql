@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS device_settings
+(
+    id uuid NOT NULL default uuid_generate_v4(),
+    user_id uuid NOT NULL,
+    device_id character varying(45) NOT NULL,
+    name text NOT NULL DEFAULT '',
+    room text NOT NULL DEFAULT '',
+    description text NOT NULL DEFAULT '',
+    hidden boolean NOT NULL DEFAULT false,
+    created_at timestamp without time zone NOT NULL DEFAULT now(),
+    updated_at timestamp without time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (id),
+    UNIQUE (user_id, device_id)
+);
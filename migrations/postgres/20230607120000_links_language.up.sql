@@ -0,0 +1 @@
+ALTER TABLE links ADD COLUMN IF NOT EXISTS language character varying(8) NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE links ADD COLUMN IF NOT EXISTS device_layout character varying(16) NOT NULL DEFAULT '';
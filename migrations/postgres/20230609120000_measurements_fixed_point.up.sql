@@ -0,0 +1,4 @@
+ALTER TABLE measurements
+    ALTER COLUMN degrees_air TYPE numeric(5, 1),
+    ALTER COLUMN degrees_floor TYPE numeric(5, 1),
+    ALTER COLUMN set_degrees_floor TYPE numeric(5, 1);
@@ -0,0 +1,8 @@
+ALTER TABLE links ADD COLUMN IF NOT EXISTS provider character varying(32) NOT NULL DEFAULT 'sst';
+ALTER TABLE links ADD COLUMN IF NOT EXISTS credentials jsonb NOT NULL DEFAULT '{}';
+ALTER TABLE links ALTER COLUMN sst_email SET DEFAULT '';
+ALTER TABLE links ALTER COLUMN sst_password SET DEFAULT '';
+
+UPDATE links
+SET credentials = jsonb_build_object('email', sst_email, 'password', sst_password)
+WHERE provider = 'sst' AND credentials = '{}';
@@ -0,0 +1,14 @@
+-- Идентификаторы генерирует приложение, схема больше не зависит от uuid-ossp и типа log_level,
+-- как и схема SQLite.
+ALTER TABLE links ALTER COLUMN id DROP DEFAULT;
+ALTER TABLE logs ALTER COLUMN id DROP DEFAULT;
+ALTER TABLE notifications ALTER COLUMN id DROP DEFAULT;
+ALTER TABLE webhooks ALTER COLUMN id DROP DEFAULT;
+ALTER TABLE webhook_deliveries ALTER COLUMN id DROP DEFAULT;
+ALTER TABLE measurements ALTER COLUMN id DROP DEFAULT;
+ALTER TABLE commands ALTER COLUMN id DROP DEFAULT;
+ALTER TABLE device_settings ALTER COLUMN id DROP DEFAULT;
+
+ALTER TABLE logs ALTER COLUMN level TYPE text USING level::text;
+ALTER TABLE logs ADD CONSTRAINT logs_level_check CHECK (level IN ('Error', 'Info'));
+DROP TYPE IF EXISTS log_level;
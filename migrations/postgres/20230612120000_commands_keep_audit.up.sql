@@ -0,0 +1,5 @@
+-- Журнал команд переживает удаление привязки: link_id обнуляется, а не удаляет записи.
+ALTER TABLE commands ALTER COLUMN link_id DROP NOT NULL;
+ALTER TABLE commands DROP CONSTRAINT IF EXISTS commands_link_id_fkey;
+ALTER TABLE commands ADD CONSTRAINT commands_link_id_fkey
+    FOREIGN KEY (link_id) REFERENCES links(id) ON UPDATE CASCADE ON DELETE SET NULL;
@@ -0,0 +1,102 @@
+-- Схема SQLite соответствует migrations/postgres по 20230611120000_app_generated_ids включительно.
+-- Идентификаторы генерируются приложением (uuid), значение по умолчанию нужно только для ручных вставок.
+CREATE TABLE links
+(
+    id text NOT NULL PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    user_id text NOT NULL,
+    sst_email text NOT NULL DEFAULT '',
+    sst_password text NOT NULL DEFAULT '',
+    provider text NOT NULL DEFAULT 'sst',
+    credentials text NOT NULL DEFAULT '{}',
+    language text NOT NULL DEFAULT '',
+    device_layout text NOT NULL DEFAULT '',
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE logs
+(
+    id text NOT NULL PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    link_id text NOT NULL REFERENCES links (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    time timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    level text NOT NULL CHECK (level IN ('Error', 'Info')),
+    message text NOT NULL
+);
+
+CREATE TABLE notifications
+(
+    id text NOT NULL PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    user_id text NOT NULL UNIQUE,
+    payload text NOT NULL,
+    discovery boolean NOT NULL DEFAULT false,
+    attempts integer NOT NULL DEFAULT 0,
+    next_attempt_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error text NOT NULL DEFAULT '',
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE webhooks
+(
+    id text NOT NULL PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    user_id text NOT NULL,
+    url text NOT NULL,
+    secret text NOT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE webhook_deliveries
+(
+    id text NOT NULL PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    webhook_id text NOT NULL REFERENCES webhooks (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    event text NOT NULL,
+    payload text NOT NULL,
+    attempt integer NOT NULL,
+    status_code integer NOT NULL DEFAULT 0,
+    error text NOT NULL DEFAULT '',
+    time timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE measurements
+(
+    id text NOT NULL PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    user_id text NOT NULL,
+    device_id text NOT NULL,
+    time timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    degrees_air real NOT NULL,
+    degrees_floor real NOT NULL,
+    set_degrees_floor real NOT NULL,
+    enabled boolean NOT NULL
+);
+
+CREATE TABLE commands
+(
+    id text NOT NULL PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    user_id text NOT NULL,
+    link_id text NOT NULL REFERENCES links (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    device_id text NOT NULL,
+    device_name text NOT NULL,
+    command text NOT NULL,
+    old_value text NOT NULL,
+    new_value text NOT NULL,
+    origin text NOT NULL,
+    request_id text NOT NULL DEFAULT '',
+    status text NOT NULL,
+    error text NOT NULL DEFAULT '',
+    time timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE device_settings
+(
+    id text NOT NULL PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    user_id text NOT NULL,
+    device_id text NOT NULL,
+    name text NOT NULL DEFAULT '',
+    room text NOT NULL DEFAULT '',
+    description text NOT NULL DEFAULT '',
+    hidden boolean NOT NULL DEFAULT false,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, device_id)
+);
@@ -0,0 +1,22 @@
+-- Журнал команд переживает удаление привязки: link_id обнуляется, а не удаляет записи.
+-- SQLite не умеет менять внешний ключ, поэтому таблица пересоздается.
+CREATE TABLE commands_new
+(
+    id text NOT NULL PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    user_id text NOT NULL,
+    link_id text REFERENCES links (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    device_id text NOT NULL,
+    device_name text NOT NULL,
+    command text NOT NULL,
+    old_value text NOT NULL,
+    new_value text NOT NULL,
+    origin text NOT NULL,
+    request_id text NOT NULL DEFAULT '',
+    status text NOT NULL,
+    error text NOT NULL DEFAULT '',
+    time timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO commands_new SELECT id, user_id, link_id, device_id, device_name, command, old_value, new_value, origin, request_id, status, error, time FROM commands;
+DROP TABLE commands;
+ALTER TABLE commands_new RENAME TO commands;
@@ -0,0 +1,12 @@
+-- Индексы как в migrations/postgres: выборки по пользователю, журналы по времени и очистка по сроку хранения.
+CREATE INDEX IF NOT EXISTS user_id_idx ON links (user_id);
+
+CREATE INDEX IF NOT EXISTS webhooks_user_id_idx ON webhooks (user_id);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_time_idx ON webhook_deliveries (webhook_id, time DESC);
+
+CREATE INDEX IF NOT EXISTS measurements_user_id_device_id_time_idx ON measurements (user_id, device_id, time);
+
+CREATE INDEX IF NOT EXISTS measurements_time_idx ON measurements (time);
+
+CREATE INDEX IF NOT EXISTS commands_user_id_time_idx ON commands (user_id, time DESC);
@@ -5,18 +5,24 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog"
 )
 
+// ErrUnauthorized токен истек или отозван, нужен повторный вход
+var ErrUnauthorized = errors.New("unauthorized")
+
 type Client struct {
 	cl     *http.Client
 	config Config
 
-	token *string
+	token  *string
+	tokenM sync.RWMutex
 }
 
 type Config struct {
@@ -52,15 +58,18 @@ func (c *Client) sendRequest(ctx context.Context, method, uri string, in, out in
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	c.tokenM.RLock()
 	if c.token != nil {
 		req.Header.Set("Authorization", "Token "+*c.token)
 	}
+	c.tokenM.RUnlock()
 
 	resp, err := c.cl.Do(req)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed make request")
 		return err
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
 		blobResponse, err := io.ReadAll(resp.Body)
@@ -69,6 +78,9 @@ func (c *Client) sendRequest(ctx context.Context, method, uri string, in, out in
 			return err
 		}
 		err = errors.New(string(blobResponse))
+		if resp.StatusCode == http.StatusUnauthorized {
+			err = fmt.Errorf("%w: %s", ErrUnauthorized, blobResponse)
+		}
 		logger.Error().Err(err).Msg("Error response")
 		return err
 	}
@@ -34,6 +34,8 @@ func (c *Client) Login(ctx context.Context, request LoginRequest) (*LoginRespons
 	if err := c.sendRequest(ctx, http.MethodPost, "/auth/login/", request, &response); err != nil {
 		return nil, err
 	}
+	c.tokenM.Lock()
 	c.token = &response.Key
+	c.tokenM.Unlock()
 	return &response, nil
 }
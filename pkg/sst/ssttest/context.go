@@ -0,0 +1,14 @@
+package ssttest
+
+import "context"
+
+type emailKey struct{}
+
+func withEmail(ctx context.Context, email string) context.Context {
+	return context.WithValue(ctx, emailKey{}, email)
+}
+
+func emailFromContext(ctx context.Context) string {
+	email, _ := ctx.Value(emailKey{}).(string)
+	return email
+}
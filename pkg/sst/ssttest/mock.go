@@ -0,0 +1,464 @@
+// Package ssttest эмулирует облако SST для разработки и тестов: аккаунты, дома, термостаты с состоянием
+// и внедрение сбоев (задержка, ответы 5xx, истечение токена).
+package ssttest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	mathrand "math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"sstcloud-alice-gateway/pkg/sst"
+)
+
+var ErrNotFound = errors.New("not found")
+
+const ambientTemperature = 20
+
+// Device состояние фейкового термостата
+type Device struct {
+	ID        int
+	Name      string
+	Type      sst.DeviceType
+	Connected bool
+	Enabled   bool
+	Setpoint  int
+	Air       int
+	Floor     int
+}
+
+// Faults внедряемые сбои, применяются ко всем запросам
+type Faults struct {
+	// Latency задержка перед ответом
+	Latency time.Duration
+	// ErrorRate вероятность ответа 500 от 0 до 1
+	ErrorRate float64
+	// FailNext количество следующих запросов, на которые вернется 500
+	FailNext int
+	// TokenTTL время жизни токена, 0 - бессрочно
+	TokenTTL time.Duration
+}
+
+type account struct {
+	password string
+	houses   []int
+}
+
+type house struct {
+	id      int
+	name    string
+	email   string
+	devices []*Device
+}
+
+type token struct {
+	email    string
+	issuedAt time.Time
+}
+
+type Mock struct {
+	mu       sync.Mutex
+	accounts map[string]*account
+	houses   map[int]*house
+	tokens   map[string]token
+	faults   Faults
+	nextID   int
+	requests int
+	router   chi.Router
+}
+
+func New() *Mock {
+	m := &Mock{
+		accounts: map[string]*account{},
+		houses:   map[int]*house{},
+		tokens:   map[string]token{},
+		nextID:   1,
+	}
+	r := chi.NewRouter()
+	r.Use(m.inject)
+	r.Post("/auth/login/", m.login)
+	r.Group(func(r chi.Router) {
+		r.Use(m.authorize)
+		r.Get("/houses/", m.listHouses)
+		r.Get("/houses/{house_id}/devices/", m.listDevices)
+		r.Post("/houses/{house_id}/devices/{device_id}/temperature/", m.setTemperature)
+		r.Post("/houses/{house_id}/devices/{device_id}/status/", m.setStatus)
+	})
+	m.router = r
+	return m
+}
+
+func (m *Mock) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.router.ServeHTTP(w, r)
+}
+
+// Server мок, запущенный на httptest сервере
+type Server struct {
+	*Mock
+	*httptest.Server
+}
+
+func NewServer() *Server {
+	m := New()
+	return &Server{
+		Mock:   m,
+		Server: httptest.NewServer(m),
+	}
+}
+
+// Config настройки клиента SST для этого сервера
+func (s *Server) Config() sst.Config {
+	return sst.Config{
+		URL:     s.URL,
+		Timeout: 5 * time.Second,
+	}
+}
+
+func (m *Mock) AddAccount(email, password string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.accounts[email] = &account{password: password}
+}
+
+func (m *Mock) AddHouse(email, name string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	acc, exists := m.accounts[email]
+	if !exists {
+		return 0, ErrNotFound
+	}
+	id := m.id()
+	m.houses[id] = &house{id: id, name: name, email: email}
+	acc.houses = append(acc.houses, id)
+	return id, nil
+}
+
+// AddDevice добавляет термостат в дом, ID устройства назначается автоматически
+func (m *Mock) AddDevice(houseID int, device Device) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, exists := m.houses[houseID]
+	if !exists {
+		return 0, ErrNotFound
+	}
+	device.ID = m.id()
+	h.devices = append(h.devices, &device)
+	return device.ID, nil
+}
+
+// Device копия текущего состояния устройства
+func (m *Mock) Device(houseID, deviceID int) (Device, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	device := m.device(houseID, deviceID)
+	if device == nil {
+		return Device{}, ErrNotFound
+	}
+	return *device, nil
+}
+
+// UpdateDevice меняет состояние устройства, например показания датчиков или связь
+func (m *Mock) UpdateDevice(houseID, deviceID int, update func(device *Device)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	device := m.device(houseID, deviceID)
+	if device == nil {
+		return ErrNotFound
+	}
+	update(device)
+	return nil
+}
+
+func (m *Mock) SetFaults(faults Faults) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.faults = faults
+}
+
+// ExpireTokens делает все выданные токены недействительными
+func (m *Mock) ExpireTokens() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokens = map[string]token{}
+}
+
+// Requests количество обработанных запросов
+func (m *Mock) Requests() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.requests
+}
+
+// Seed создает аккаунт с домами и включенными термостатами MCS 350
+func (m *Mock) Seed(email, password string, houses, devicesPerHouse int) error {
+	m.AddAccount(email, password)
+	for i := 1; i <= houses; i++ {
+		houseID, err := m.AddHouse(email, fmt.Sprintf("House %d", i))
+		if err != nil {
+			return err
+		}
+		for j := 1; j <= devicesPerHouse; j++ {
+			if _, err := m.AddDevice(houseID, Device{
+				Name:      fmt.Sprintf("Floor %d", j),
+				Type:      sst.MCS350,
+				Connected: true,
+				Enabled:   true,
+				Setpoint:  28,
+				Air:       ambientTemperature,
+				Floor:     ambientTemperature,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Step сдвигает показания на градус: к уставке у включенных термостатов, к комнатной у выключенных
+func (m *Mock) Step() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, h := range m.houses {
+		for _, device := range h.devices {
+			if !device.Connected {
+				continue
+			}
+			target := ambientTemperature
+			if device.Enabled {
+				target = device.Setpoint
+			}
+			device.Floor = approach(device.Floor, target)
+			device.Air = approach(device.Air, (target+ambientTemperature)/2)
+		}
+	}
+}
+
+func approach(value, target int) int {
+	switch {
+	case value < target:
+		return value + 1
+	case value > target:
+		return value - 1
+	}
+	return value
+}
+
+func (m *Mock) id() int {
+	id := m.nextID
+	m.nextID++
+	return id
+}
+
+func (m *Mock) device(houseID, deviceID int) *Device {
+	h, exists := m.houses[houseID]
+	if !exists {
+		return nil
+	}
+	for _, device := range h.devices {
+		if device.ID == deviceID {
+			return device
+		}
+	}
+	return nil
+}
+
+func (m *Mock) inject(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		m.requests++
+		faults := m.faults
+		fail := m.faults.FailNext > 0 || (faults.ErrorRate > 0 && mathrand.Float64() < faults.ErrorRate)
+		if m.faults.FailNext > 0 {
+			m.faults.FailNext--
+		}
+		m.mu.Unlock()
+		if faults.Latency > 0 {
+			select {
+			case <-time.After(faults.Latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if fail {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"detail": "Internal server error."})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (m *Mock) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.Header.Get("Authorization"), "Token ")
+		m.mu.Lock()
+		t, exists := m.tokens[key]
+		if exists && m.faults.TokenTTL > 0 && time.Since(t.issuedAt) > m.faults.TokenTTL {
+			delete(m.tokens, key)
+			exists = false
+		}
+		m.mu.Unlock()
+		if !exists {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"detail": "Invalid token."})
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(withEmail(r.Context(), t.email)))
+	})
+}
+
+func (m *Mock) login(w http.ResponseWriter, r *http.Request) {
+	var req sst.LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"detail": err.Error()})
+		return
+	}
+	email := req.EMail
+	if email == "" {
+		email = req.Username
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	acc, exists := m.accounts[email]
+	if !exists || acc.password != req.Password {
+		writeJSON(w, http.StatusBadRequest, map[string][]string{"non_field_errors": {"Unable to log in with provided credentials."}})
+		return
+	}
+	key := newToken()
+	m.tokens[key] = token{email: email, issuedAt: time.Now()}
+	writeJSON(w, http.StatusOK, sst.LoginResponse{Key: key})
+}
+
+func (m *Mock) listHouses(w http.ResponseWriter, r *http.Request) {
+	email := emailFromContext(r.Context())
+	m.mu.Lock()
+	result := make([]sst.House, 0)
+	for _, id := range m.accounts[email].houses {
+		h := m.houses[id]
+		result = append(result, sst.House{ID: h.id, Name: h.name, InHome: true})
+	}
+	m.mu.Unlock()
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (m *Mock) listDevices(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	h := m.ownHouse(r)
+	if h == nil {
+		m.mu.Unlock()
+		writeJSON(w, http.StatusNotFound, map[string]string{"detail": "Not found."})
+		return
+	}
+	result := make([]sst.Device, 0, len(h.devices))
+	for _, device := range h.devices {
+		result = append(result, toSST(h.id, device))
+	}
+	m.mu.Unlock()
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (m *Mock) setTemperature(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TemperatureManual *int `json:"temperature_manual"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.TemperatureManual == nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"detail": "temperature_manual is required"})
+		return
+	}
+	m.updateOwnDevice(w, r, func(device *Device) {
+		device.Setpoint = *req.TemperatureManual
+	})
+}
+
+func (m *Mock) setStatus(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Status sst.DeviceStatus `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || (req.Status != sst.DeviceStatusOn && req.Status != sst.DeviceStatusOff) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"detail": "status must be on or off"})
+		return
+	}
+	m.updateOwnDevice(w, r, func(device *Device) {
+		device.Enabled = req.Status == sst.DeviceStatusOn
+	})
+}
+
+func (m *Mock) updateOwnDevice(w http.ResponseWriter, r *http.Request, update func(device *Device)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h := m.ownHouse(r)
+	deviceID, _ := strconv.Atoi(chi.URLParam(r, "device_id"))
+	var device *Device
+	if h != nil {
+		device = m.device(h.id, deviceID)
+	}
+	if device == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"detail": "Not found."})
+		return
+	}
+	if !device.Connected {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"detail": "Device is not connected."})
+		return
+	}
+	update(device)
+	writeJSON(w, http.StatusOK, map[string]string{})
+}
+
+// ownHouse дом из URL, если он принадлежит владельцу токена; вызывается под m.mu
+func (m *Mock) ownHouse(r *http.Request) *house {
+	houseID, err := strconv.Atoi(chi.URLParam(r, "house_id"))
+	if err != nil {
+		return nil
+	}
+	h, exists := m.houses[houseID]
+	if !exists || h.email != emailFromContext(r.Context()) {
+		return nil
+	}
+	return h
+}
+
+func toSST(houseID int, device *Device) sst.Device {
+	var parsed sst.DeviceTermParsedConfiguration
+	parsed.Settings.Mode = sst.DeviceModeManual
+	parsed.Settings.Status = sst.DeviceStatusOff
+	if device.Enabled {
+		parsed.Settings.Status = sst.DeviceStatusOn
+	}
+	parsed.Settings.TemperatureManual = device.Setpoint
+	parsed.CurrentTemperature.TemperatureAir = device.Air
+	parsed.CurrentTemperature.TemperatureFloor = device.Floor
+	parsed.AccessStatus = sst.DeviceStatusAvailable
+	blob, _ := json.Marshal(parsed)
+	return sst.Device{
+		ID:                  device.ID,
+		House:               houseID,
+		Name:                device.Name,
+		Type:                device.Type,
+		IsActive:            true,
+		IsConnected:         device.Connected,
+		MacAddress:          fmt.Sprintf("00:00:00:00:%02x:%02x", houseID%256, device.ID%256),
+		ParsedConfiguration: string(blob),
+	}
+}
+
+func newToken() string {
+	blob := make([]byte, 20)
+	if _, err := rand.Read(blob); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(blob)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,194 @@
+package ssttest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"sstcloud-alice-gateway/pkg/sst"
+	"sstcloud-alice-gateway/pkg/sst/ssttest"
+)
+
+const (
+	email    = "user@example.com"
+	password = "secret"
+)
+
+func setup(t *testing.T) (*ssttest.Server, *sst.Client, int, int) {
+	t.Helper()
+	srv := ssttest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddAccount(email, password)
+	houseID, err := srv.AddHouse(email, "Дача")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deviceID, err := srv.AddDevice(houseID, ssttest.Device{
+		Name:      "Кухня",
+		Type:      sst.MCS350,
+		Connected: true,
+		Setpoint:  25,
+		Air:       21,
+		Floor:     23,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := sst.New(srv.Config())
+	if _, err := client.Login(context.Background(), sst.LoginRequest{EMail: email, Password: password}); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	return srv, client, houseID, deviceID
+}
+
+func TestLoginRejectsWrongPassword(t *testing.T) {
+	srv := ssttest.NewServer()
+	defer srv.Close()
+	srv.AddAccount(email, password)
+	client := sst.New(srv.Config())
+	if _, err := client.Login(context.Background(), sst.LoginRequest{EMail: email, Password: "wrong"}); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestHousesAndDevices(t *testing.T) {
+	_, client, houseID, deviceID := setup(t)
+	ctx := context.Background()
+	houses, err := client.Houses(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(houses) != 1 || houses[0].ID != houseID || houses[0].Name != "Дача" {
+		t.Fatalf("unexpected houses %+v", houses)
+	}
+	devices, err := client.Devices(ctx, houseID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(devices) != 1 {
+		t.Fatalf("expected 1 device, got %d", len(devices))
+	}
+	device := devices[0]
+	if device.ID != deviceID || device.Type != sst.MCS350 || !device.IsConnected {
+		t.Fatalf("unexpected device %+v", device)
+	}
+	parsed := device.TermParsedConfiguration
+	if parsed == nil {
+		t.Fatal("parsed configuration is missing")
+	}
+	if parsed.Settings.TemperatureManual != 25 || parsed.CurrentTemperature.TemperatureAir != 21 || parsed.CurrentTemperature.TemperatureFloor != 23 {
+		t.Fatalf("unexpected temperatures %+v", parsed)
+	}
+	if parsed.Settings.Status != sst.DeviceStatusOff {
+		t.Fatalf("expected device off, got %s", parsed.Settings.Status)
+	}
+}
+
+func TestForeignHouseIsHidden(t *testing.T) {
+	srv, client, _, _ := setup(t)
+	srv.AddAccount("other@example.com", password)
+	otherHouse, err := srv.AddHouse("other@example.com", "Чужой")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Devices(context.Background(), otherHouse); err == nil {
+		t.Fatal("expected error for foreign house")
+	}
+}
+
+func TestCommandsChangeState(t *testing.T) {
+	srv, client, houseID, deviceID := setup(t)
+	ctx := context.Background()
+	if err := client.PowerStatus(ctx, houseID, deviceID, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Temperature(ctx, houseID, deviceID, 30); err != nil {
+		t.Fatal(err)
+	}
+	device, err := srv.Device(houseID, deviceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !device.Enabled || device.Setpoint != 30 {
+		t.Fatalf("unexpected state %+v", device)
+	}
+	srv.Step()
+	device, _ = srv.Device(houseID, deviceID)
+	if device.Floor != 24 {
+		t.Fatalf("expected floor to approach setpoint, got %d", device.Floor)
+	}
+}
+
+func TestDisconnectedDeviceRejectsCommands(t *testing.T) {
+	srv, client, houseID, deviceID := setup(t)
+	if err := srv.UpdateDevice(houseID, deviceID, func(device *ssttest.Device) { device.Connected = false }); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.PowerStatus(context.Background(), houseID, deviceID, true); err == nil {
+		t.Fatal("expected error for disconnected device")
+	}
+}
+
+func TestFaults(t *testing.T) {
+	tests := []struct {
+		name   string
+		faults ssttest.Faults
+		expire bool
+		check  func(t *testing.T, err error)
+	}{
+		{
+			name:   "server error",
+			faults: ssttest.Faults{FailNext: 1},
+			check: func(t *testing.T, err error) {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+			},
+		},
+		{
+			name:   "expired token",
+			expire: true,
+			check: func(t *testing.T, err error) {
+				if !errors.Is(err, sst.ErrUnauthorized) {
+					t.Fatalf("expected ErrUnauthorized, got %v", err)
+				}
+			},
+		},
+		{
+			name:   "token ttl",
+			faults: ssttest.Faults{TokenTTL: time.Nanosecond},
+			check: func(t *testing.T, err error) {
+				if !errors.Is(err, sst.ErrUnauthorized) {
+					t.Fatalf("expected ErrUnauthorized, got %v", err)
+				}
+			},
+		},
+		{
+			name:   "latency over client timeout",
+			faults: ssttest.Faults{Latency: 200 * time.Millisecond},
+			check: func(t *testing.T, err error) {
+				if err == nil {
+					t.Fatal("expected timeout")
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, _, _, _ := setup(t)
+			config := srv.Config()
+			config.Timeout = 50 * time.Millisecond
+			client := sst.New(config)
+			if _, err := client.Login(context.Background(), sst.LoginRequest{EMail: email, Password: password}); err != nil {
+				t.Fatal(err)
+			}
+			srv.SetFaults(tt.faults)
+			if tt.expire {
+				srv.ExpireTokens()
+			}
+			_, err := client.Houses(context.Background())
+			tt.check(t, err)
+		})
+	}
+}